@@ -0,0 +1,88 @@
+package merkle
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// WithBloomFilter makes the tree keep a Bloom filter of its leaves with the
+// provided false positive rate, e.g. 0.01, so that looking up leaves which
+// are not part of the tree, i.e. Contains and Proof, returns straight away
+// in O(1) for the vast majority of them rather than binary searching.
+// Leaves passing the filter are still confirmed by the search.
+//
+// The filter takes about 1.44*log2(1/rate) bits per leaf, that is, roughly
+// 1.2 bytes per leaf at 1% or 1.8 bytes per leaf at 0.1%, on top of the tree.
+// Rates outside of (0, 1) keep no filter, neither do trees built WithoutLeaves.
+//
+// Insert and Update add leaves to the filter, the replaced ones staying in,
+// and rebuild it for twice the number of leaves once it holds as many leaves
+// as it was sized for, keeping the false positive rate within the provided one.
+func WithBloomFilter(falsePositiveRate float64) Option {
+	return func(c *config) {
+		c.bloomRate = falsePositiveRate
+	}
+}
+
+// bloomFilter is a Bloom filter of hashed leaves.
+type bloomFilter struct {
+	bits []uint64
+	// k is the number of bits set for each leaf.
+	k int
+	// n is the number of leaves the filter is sized for,
+	// added the number of leaves added so far.
+	n, added int
+}
+
+// newBloomFilter makes a bloomFilter sized for n leaves
+// with the provided false positive rate.
+func newBloomFilter(n int, rate float64) *bloomFilter {
+	if n < 1 {
+		n = 1
+	}
+	m := math.Ceil(-float64(n) * math.Log(rate) / (math.Ln2 * math.Ln2))
+	k := int(math.Round(m / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &bloomFilter{bits: make([]uint64, (int(m)+63)/64), k: k, n: n}
+}
+
+// full tells whether the filter holds as many leaves as it was sized for.
+func (f *bloomFilter) full() bool {
+	return f.added >= f.n
+}
+
+// add sets the bits of the provided hashed leaf.
+func (f *bloomFilter) add(hl []byte) {
+	f.added++
+	f.each(hl, func(i uint64) bool {
+		f.bits[i/64] |= 1 << (i % 64)
+		return true
+	})
+}
+
+// mayContain tells whether the provided hashed leaf may have been added,
+// false meaning it definitely has not.
+func (f *bloomFilter) mayContain(hl []byte) bool {
+	return f.each(hl, func(i uint64) bool {
+		return f.bits[i/64]&(1<<(i%64)) != 0
+	})
+}
+
+// each calls fn with the k bit indexes of the provided hashed leaf, derived
+// by double hashing, stopping as soon as fn returns false. It reports whether
+// fn returned true for all of them.
+func (f *bloomFilter) each(hl []byte, fn func(i uint64) bool) bool {
+	h := fnv.New64a()
+	h.Write(hl)
+	h1 := h.Sum64()
+	h2 := h1>>32 | h1<<32 | 1
+	m := uint64(len(f.bits)) * 64
+	for j := 0; j < f.k; j++ {
+		if !fn((h1 + uint64(j)*h2) % m) {
+			return false
+		}
+	}
+	return true
+}
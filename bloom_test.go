@@ -0,0 +1,83 @@
+package merkle
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestWithBloomFilter(t *testing.T) {
+	data := make([]string, 1000)
+	for i := range data {
+		data[i] = strconv.Itoa(i)
+	}
+	hl := hashStringSlice(algo, data...)
+	tree := NewTree(algo, hl, WithBloomFilter(0.01))
+
+	t.Run("Should Contain And Prove Every Leaf", func(t *testing.T) {
+		for _, l := range hl {
+			if !tree.Contains(l) || tree.Proof(l).Len() == 0 {
+				t.Fatalf("expected leaf %x to be found", l)
+			}
+		}
+	})
+
+	t.Run("Should Rule Out Most Non Members", func(t *testing.T) {
+		positives := 0
+		for i := len(data); i < 11*len(data); i++ {
+			l := hashStringSlice(algo, strconv.Itoa(i))[0]
+			if tree.bloom.mayContain(l) {
+				positives++
+			}
+			if tree.Contains(l) {
+				t.Fatalf("expected leaf %x not to be found", l)
+			}
+		}
+		// 1% of 10000 non members, with a generous margin.
+		if positives > 200 {
+			t.Errorf("expected about 100 false positives, got %d", positives)
+		}
+	})
+
+	t.Run("Should Track Inserted And Updated Leaves", func(t *testing.T) {
+		tree := NewTree(algo, hl[:10], WithBloomFilter(0.01))
+		tree.Insert(hl[10])
+		if err := tree.Update(hl[0], hl[11]); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if !tree.Contains(hl[10]) || !tree.Contains(hl[11]) || tree.Contains(hl[0]) {
+			t.Errorf("expected inserted and updated leaves only to be found")
+		}
+	})
+
+	t.Run("Should Keep Rate Once Grown", func(t *testing.T) {
+		tree := NewTree(algo, hl[:10], WithBloomFilter(0.01))
+		for _, l := range hl[10:] {
+			tree.Insert(l)
+		}
+		if err := tree.Update(hl[0], hashStringSlice(algo, "updated")[0]); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		for _, l := range hl[1:] {
+			if !tree.Contains(l) {
+				t.Fatalf("expected leaf %x to be found", l)
+			}
+		}
+		positives := 0
+		for i := len(data); i < 11*len(data); i++ {
+			if tree.bloom.mayContain(hashStringSlice(algo, strconv.Itoa(i))[0]) {
+				positives++
+			}
+		}
+		if positives > 200 {
+			t.Errorf("expected about 100 false positives, got %d", positives)
+		}
+	})
+
+	t.Run("Should Keep No Filter For Invalid Rate", func(t *testing.T) {
+		for _, rate := range []float64{0, 1, -0.5} {
+			if tree := NewTree(algo, hl[:10], WithBloomFilter(rate)); tree.bloom != nil {
+				t.Errorf("expected no filter for rate %v", rate)
+			}
+		}
+	})
+}
@@ -0,0 +1,33 @@
+package merkle
+
+import (
+	"bytes"
+	"hash"
+)
+
+// BindRoot binds the provided merkle root to a domain, e.g. a tenant or
+// a context name, returning H(domain || root). Publishing the bound root
+// rather than the plain one prevents proofs from being reused across
+// contexts, see VerifyBound.
+func BindRoot(h hash.Hash, root, domain []byte) []byte {
+	h.Reset()
+	h.Write(domain)
+	h.Write(root)
+	return h.Sum(nil)
+}
+
+// BoundRoot returns the tree merkle root bound to the provided domain,
+// see BindRoot.
+func (t Tree) BoundRoot(domain []byte) []byte {
+	defer t.c.lock()()
+	return BindRoot(t.h, t.root.val, domain)
+}
+
+// VerifyBound verifies whether the provided proof for leaf is valid against
+// the boundRoot of the provided domain, reconstructing the plain root the
+// same way Verify does and binding it to domain before comparing, hence
+// proofs of a context never verify against the bound root of another one.
+func VerifyBound(algo hash.Hash, leaf, boundRoot []byte, proof [][]byte, domain []byte) bool {
+	root := newConfig().reconstruct(algo, leaf, proof)
+	return bytes.Equal(BindRoot(algo, root, domain), boundRoot)
+}
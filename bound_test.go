@@ -0,0 +1,29 @@
+package merkle
+
+import (
+	"testing"
+)
+
+func TestVerifyBound(t *testing.T) {
+	tenantA, tenantB := []byte("tenant-a"), []byte("tenant-b")
+	boundA := oddLeavesTree.BoundRoot(tenantA)
+
+	t.Run("Should Verify Within Domain", func(t *testing.T) {
+		for _, l := range oddLeavesTree.leaves {
+			if !VerifyBound(algo, l.val, boundA, oddLeavesTree.Proof(l.val).ToByteArrays(), tenantA) {
+				t.Errorf("proof for leaf %s should have been valid", l)
+			}
+		}
+	})
+
+	t.Run("Should Not Verify Across Domains", func(t *testing.T) {
+		l := oddLeavesTree.leaves[0].val
+		proof := oddLeavesTree.Proof(l).ToByteArrays()
+		if VerifyBound(algo, l, boundA, proof, tenantB) {
+			t.Errorf("proof should have been invalid in another domain")
+		}
+		if VerifyBound(algo, l, oddLeavesTree.Root().Bytes(), proof, tenantA) {
+			t.Errorf("proof should have been invalid against the plain root")
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package merkle
+
+import (
+	"hash"
+)
+
+// BufferedTree is a Tree whose inserts are buffered and merged in batches,
+// which suits workloads inserting leaves one at a time while still querying
+// the root and proofs frequently.
+//
+// Inserted leaves are held unmerged until threshold of them are buffered,
+// at which point they're sorted and merged into the leaves at once, and
+// the tree is rebuilt. Being the rebuild linear in the number of leaves n,
+// the amortized cost of an insert is O(n/threshold + log threshold) rather
+// than the O(n) of Tree.Insert. The Tree, thus Root and proofs, reflects
+// the leaves as of the last merge, Flush merges the buffered ones straight away.
+//
+// Same as Tree.Insert, inserts have no effect on trees built WithoutLeaves.
+// It's not safe for concurrent use.
+type BufferedTree struct {
+	*Tree
+	buffer    Nodes
+	threshold int
+}
+
+// NewBufferedTree makes a new BufferedTree with the provided hashing
+// algorithm and set of hashed leaves, merging inserted leaves
+// every threshold of them. A threshold lesser than 1 is treated as 1.
+func NewBufferedTree(h hash.Hash, hl [][]byte, threshold int, opts ...Option) *BufferedTree {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &BufferedTree{
+		Tree:      NewTree(h, hl, opts...),
+		buffer:    make(Nodes, 0, threshold),
+		threshold: threshold,
+	}
+}
+
+// Insert buffers the provided hashed leaf, merging
+// the buffered leaves once threshold is reached.
+// It returns ErrMaxDepth, leaving the leaf out, if the tree would
+// exceed the depth set WithMaxDepth once the buffer is merged.
+func (t *BufferedTree) Insert(hl []byte) error {
+	if t.c.withoutLeaves {
+		return nil
+	}
+	if t.c.exceedsDepth(t.leafCountWith(append(t.buffer.ToByteArrays(), hl)...)) {
+		return ErrMaxDepth
+	}
+	t.buffer = append(t.buffer, newNode(hl))
+	if len(t.buffer) >= t.threshold {
+		t.Flush()
+	}
+	return nil
+}
+
+// Pending returns the number of buffered leaves not merged yet.
+func (t *BufferedTree) Pending() int {
+	return len(t.buffer)
+}
+
+// Flush merges the buffered leaves, if any, and rebuilds the tree.
+// Trees whose leaves are padded or collapsed, e.g. WithMultiplicities,
+// are rebuilt out of the leaves they were built from plus the buffered
+// ones instead, same as NewTree would build them.
+func (t *BufferedTree) Flush() {
+	if len(t.buffer) == 0 {
+		return
+	}
+	defer func() { t.buffer = t.buffer[:0] }()
+	if t.c.derived() {
+		t.rebuild(append(t.unpadded(), t.buffer.ToByteArrays()...))
+		return
+	}
+	if t.c.mode.sortsLeaves() {
+		t.c.sortNodes(t.buffer)
+	}
+	algo := t.algo
+	t.Tree = newTree(t.h, t.c.mergeLeaves(t.leaves, t.buffer), t.c)
+	t.algo = algo
+}
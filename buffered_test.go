@@ -0,0 +1,69 @@
+package merkle
+
+import (
+	"testing"
+)
+
+func TestBufferedTree(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+	tree := NewBufferedTree(algo, hl[4:], 2)
+
+	t.Run("Should Buffer Inserts Until Threshold", func(t *testing.T) {
+		tree.Insert(hl[2])
+		tree.Insert(hl[0])
+		tree.Insert(hl[3])
+		if tree.Pending() != 1 {
+			t.Errorf("expected 1 pending leaf, got %d", tree.Pending())
+		}
+		exp := NewTree(algo, [][]byte{hl[4], hl[2], hl[0]}).Root().String()
+		if act := tree.Root().String(); act != exp {
+			t.Errorf("expected merkle root should have been %s, got %s", exp, act)
+		}
+	})
+
+	t.Run("Should Return Expected Merkle Root Once Flushed", func(t *testing.T) {
+		tree.Insert(hl[1])
+		tree.Flush()
+		if tree.Pending() != 0 {
+			t.Errorf("expected no pending leaves, got %d", tree.Pending())
+		}
+		if exp, act := oddLeavesTree.Root().String(), tree.Root().String(); act != exp {
+			t.Errorf("expected merkle root should have been %s, got %s", exp, act)
+		}
+		for _, l := range hl {
+			if !Verify(algo, l, tree.Root().Bytes(), tree.Proof(l).ToByteArrays()) {
+				t.Errorf("proof for %x should have been valid", l)
+			}
+		}
+	})
+
+	t.Run("Should Count Flushed Leaves With Multiplicities", func(t *testing.T) {
+		tree := NewBufferedTree(algo, hl[:2], 2, WithMultiplicities(), WithBloomFilter(0.01))
+		tree.Insert(hl[0])
+		tree.Insert(hl[2])
+		exp := NewTree(algo, [][]byte{hl[0], hl[1], hl[0], hl[2]}, WithMultiplicities())
+		if act := tree.Root().String(); act != exp.Root().String() {
+			t.Errorf("expected merkle root should have been %s, got %s", exp.Root(), act)
+		}
+		proof, count, err := tree.ProveMultiplicity(hl[0])
+		if err != nil || count != 2 {
+			t.Fatalf("expected a count of 2, got %d, %v", count, err)
+		}
+		if !VerifyMultiplicity(algo, hl[0], count, tree.Root().Bytes(), proof.ToByteArrays()) {
+			t.Errorf("proof for %x should have been valid", hl[0])
+		}
+		if !tree.Contains(multiplicityLeaf(algo, hl[2], 1)) {
+			t.Errorf("expected the flushed leaf to pass the Bloom filter")
+		}
+	})
+
+	t.Run("Should Pad Flushed Leaves", func(t *testing.T) {
+		tree := NewBufferedTree(algo, hl[:3], 2, WithEmptyHashPadding())
+		tree.Insert(hl[3])
+		tree.Insert(hl[4])
+		exp := NewTree(algo, hl, WithEmptyHashPadding())
+		if act := tree.Root().String(); act != exp.Root().String() {
+			t.Errorf("expected merkle root should have been %s, got %s", exp.Root(), act)
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// ErrIncompatibleOptions is returned by Builder when the configured
+// Option(s) can't be combined with one another.
+var ErrIncompatibleOptions = errors.New("merkle: incompatible options")
+
+// Builder configures and builds trees through method chaining, e.g.
+//
+//	tree, err := merkle.NewBuilder().
+//		WithHasher(sha256.New).
+//		WithMode(merkle.ModeRFC6962).
+//		WithEmptyHashPadding().
+//		Build(leaves)
+//
+// Unlike NewTree, which panics or silently ignores meaningless Option(s),
+// Build validates the whole configuration at once and returns an error.
+// A Builder can be reused to build many trees, each with its own hasher.
+type Builder struct {
+	newHash func() hash.Hash
+	name    string
+	sorted  bool
+	mode    Option
+	opts    []Option
+}
+
+// NewBuilder makes a new Builder hashing with sha256 by default.
+func NewBuilder() *Builder {
+	return &Builder{newHash: sha256.New}
+}
+
+// WithHasher sets the constructor of the hashing algorithm,
+// which is called once per built tree.
+func (b *Builder) WithHasher(newHash func() hash.Hash) *Builder {
+	b.newHash = newHash
+	return b
+}
+
+// WithName names the hashing algorithm, see NewTreeNamed.
+func (b *Builder) WithName(name string) *Builder {
+	b.name = name
+	return b
+}
+
+// WithMode sets the scheme trees are constructed with, see WithMode.
+// The Mode is applied before any other Option, whatever the order they're
+// set in, so that it doesn't reset the ones set before it, e.g. WithLeafPrefix.
+func (b *Builder) WithMode(m Mode) *Builder {
+	b.mode = WithMode(m)
+	return b
+}
+
+// WithEmptyHashPadding pads the leaves with H(""), see WithEmptyHashPadding.
+func (b *Builder) WithEmptyHashPadding() *Builder {
+	return b.WithOptions(WithEmptyHashPadding())
+}
+
+// WithBlindingPadding pads the leaves with blinding leaves,
+// see WithBlindingPadding.
+func (b *Builder) WithBlindingPadding(targetSize int, rng io.Reader) *Builder {
+	return b.WithOptions(WithBlindingPadding(targetSize, rng))
+}
+
+// WithLeafPrefix sets the prefix leaves data is hashed with,
+// see WithLeafPrefix.
+func (b *Builder) WithLeafPrefix(prefix []byte) *Builder {
+	return b.WithOptions(WithLeafPrefix(prefix))
+}
+
+// WithIndexedLeaves mixes the index of the data into leaves hashes,
+// see WithIndexedLeaves. It only applies to BuildFromData.
+func (b *Builder) WithIndexedLeaves() *Builder {
+	return b.WithOptions(WithIndexedLeaves())
+}
+
+// WithMaxDepth bounds the depth of the trees, see WithMaxDepth.
+func (b *Builder) WithMaxDepth(d int) *Builder {
+	return b.WithOptions(WithMaxDepth(d))
+}
+
+// WithSorted trusts the leaves provided to Build to be already
+// sorted, skipping the sorting step, see NewTreeSorted.
+func (b *Builder) WithSorted() *Builder {
+	b.sorted = true
+	return b
+}
+
+// WithOptions applies the provided Option(s) after the ones set so far.
+func (b *Builder) WithOptions(opts ...Option) *Builder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// options returns the Builder Option(s), the Mode coming first.
+func (b *Builder) options() []Option {
+	if b.mode == nil {
+		return b.opts
+	}
+	return append([]Option{b.mode}, b.opts...)
+}
+
+// Build builds up a new merkle tree, same as NewTree does, with the provided
+// hashed leaves. It returns ErrNoLeaves if no leaves are provided, ErrHashSize
+// if any of them is not as big as the hasher output, ErrMaxDepth if the tree
+// would be too deep and ErrIncompatibleOptions if the configuration is not
+// consistent, e.g. padding leaves trusted to be sorted.
+func (b *Builder) Build(hl [][]byte) (*Tree, error) {
+	h := b.newHash()
+	// leaves are counted as collapsed, if so, config counts them as padded.
+	c, err := b.config(newConfig(b.options()...).leafCount(hl))
+	if err != nil {
+		return nil, err
+	}
+	if c.indexedLeaves {
+		return nil, fmt.Errorf("%w: indexed leaves need data, see BuildFromData", ErrIncompatibleOptions)
+	}
+	for _, l := range hl {
+		if len(l) != h.Size() {
+			return nil, ErrHashSize
+		}
+	}
+	var t *Tree
+	if b.sorted {
+		t = NewTreeSorted(h, hl, b.options()...)
+	} else {
+		t = NewTree(h, hl, b.options()...)
+	}
+	t.algo = b.name
+	return t, nil
+}
+
+// BuildFromData builds up a new merkle tree, same as NewTreeFromData does,
+// with the provided raw leaves data. It returns the same errors as Build.
+func (b *Builder) BuildFromData(data [][]byte) (*Tree, error) {
+	if b.sorted {
+		return nil, fmt.Errorf("%w: data can't be trusted to be sorted once hashed", ErrIncompatibleOptions)
+	}
+	if _, err := b.config(len(data)); err != nil {
+		return nil, err
+	}
+	t := NewTreeFromData(b.newHash(), data, b.options()...)
+	t.algo = b.name
+	return t, nil
+}
+
+// config applies the Builder Option(s), validating them for n leaves,
+// which are checked against the maximum depth once padded.
+func (b *Builder) config(n int) (*config, error) {
+	c := newConfig(b.options()...)
+	switch {
+	case n == 0:
+		return nil, ErrNoLeaves
+	case c.exceedsDepth(n):
+		return nil, ErrMaxDepth
+	case c.padding > 0 && c.emptyPadding:
+		return nil, fmt.Errorf("%w: blinding and empty hash padding", ErrIncompatibleOptions)
+	case c.multiplicities && !c.mode.sortsLeaves():
+		return nil, fmt.Errorf("%w: multiplicities in %s mode", ErrIncompatibleOptions, c.mode)
+	case b.sorted && (c.padding > 0 || c.emptyPadding || c.multiplicities):
+		return nil, fmt.Errorf("%w: leaves trusted to be sorted can't be padded nor collapsed", ErrIncompatibleOptions)
+	}
+	return c, nil
+}
@@ -0,0 +1,87 @@
+package merkle
+
+import (
+	"crypto/sha512"
+	"errors"
+	"testing"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+
+	t.Run("Should Build Same Tree As NewTree", func(t *testing.T) {
+		tree, err := NewBuilder().WithMode(ModeRFC6962).WithEmptyHashPadding().Build(hl)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		exp := NewTree(algo, hl, WithMode(ModeRFC6962), WithEmptyHashPadding())
+		if tree.Root().String() != exp.Root().String() {
+			t.Errorf("expected merkle root to be %s, got %s", exp.Root(), tree.Root())
+		}
+	})
+
+	t.Run("Should Build With Hasher, Name And Sorted Leaves", func(t *testing.T) {
+		h := sha512.New()
+		sorted := NewTree(h, hashStringSlice(h, "a", "b", "c")).leaves.ToByteArrays()
+		tree, err := NewBuilder().WithHasher(sha512.New).WithName("sha-512").WithSorted().Build(sorted)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if tree.AlgoName() != "sha-512" || tree.HashSize() != sha512.Size {
+			t.Errorf("unexpected algorithm %q of size %d", tree.AlgoName(), tree.HashSize())
+		}
+	})
+
+	t.Run("Should Build From Data", func(t *testing.T) {
+		data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+		tree, err := NewBuilder().WithMode(ModeOrdered).WithIndexedLeaves().BuildFromData(data)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		exp := NewTreeFromData(algo, data, WithMode(ModeOrdered), WithIndexedLeaves())
+		if tree.Root().String() != exp.Root().String() {
+			t.Errorf("expected merkle root to be %s, got %s", exp.Root(), tree.Root())
+		}
+	})
+
+	t.Run("Should Apply Mode Before Other Options", func(t *testing.T) {
+		data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+		tree, err := NewBuilder().WithLeafPrefix([]byte{0x07}).WithOptions(WithLengthPrefix()).WithMode(ModeOrdered).BuildFromData(data)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		exp := NewTreeFromData(algo, data, WithMode(ModeOrdered), WithLeafPrefix([]byte{0x07}), WithLengthPrefix())
+		if tree.Root().String() != exp.Root().String() {
+			t.Errorf("expected merkle root to be %s, got %s", exp.Root(), tree.Root())
+		}
+	})
+
+	for name, tc := range map[string]struct {
+		b   *Builder
+		hl  [][]byte
+		exp error
+	}{
+		"No Leaves":           {NewBuilder(), nil, ErrNoLeaves},
+		"Wrong Hash Size":     {NewBuilder().WithHasher(sha512.New), hl, ErrHashSize},
+		"Max Depth":           {NewBuilder().WithMaxDepth(2), hl, ErrMaxDepth},
+		"Padded Max Depth":    {NewBuilder().WithMaxDepth(3).WithBlindingPadding(64, nil), hl, ErrMaxDepth},
+		"Both Paddings":       {NewBuilder().WithEmptyHashPadding().WithBlindingPadding(8, nil), hl, ErrIncompatibleOptions},
+		"Sorted And Padded":   {NewBuilder().WithSorted().WithEmptyHashPadding(), hl, ErrIncompatibleOptions},
+		"Indexed Leaves":      {NewBuilder().WithIndexedLeaves(), hl, ErrIncompatibleOptions},
+		"Positional Multiset": {NewBuilder().WithMode(ModeOrdered).WithOptions(WithMultiplicities()), hl, ErrIncompatibleOptions},
+	} {
+		t.Run("With "+name+" Should Return Error", func(t *testing.T) {
+			if _, err := tc.b.Build(tc.hl); !errors.Is(err, tc.exp) {
+				t.Errorf("expected %v, got %v", tc.exp, err)
+			}
+		})
+	}
+
+	t.Run("Should Count Collapsed Leaves Against Max Depth", func(t *testing.T) {
+		// 9 leaves collapse into 8 distinct ones, that is, 3 levels.
+		dup := append(hashStringSlice(algo, "a", "b", "c", "d", "e", "f", "g", "h"), hl[0])
+		if _, err := NewBuilder().WithMaxDepth(3).WithOptions(WithMultiplicities()).Build(dup); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// ErrHashNotAllowed is returned when a Bundle names a hashing algorithm
+// its verifier doesn't allow, see VerifyBundleBytes.
+var ErrHashNotAllowed = errors.New("merkle: hashing algorithm not allowed")
+
+// defaultAllowedHashes are the hashing algorithms VerifyBundleBytes allows
+// unless told otherwise, leaving the broken md5 and sha1 out.
+var defaultAllowedHashes = []string{"sha224", "sha256", "sha384", "sha512", "keccak256"}
+
+// Bundle is a self-contained and verifiable merkle proof artifact,
+// it pairs a leaf and its proof with the merkle root they prove against.
+// It's marshalled to JSON with every hash encoded as an hexadecimal string.
+//
+// Bundles carry the Mode of the tree they've been built from, as well
+// as the leaf Index and the tree Size needed by positional modes.
+// Algo names the hashing algorithm, as registered with RegisterHash,
+// which makes bundles verifiable without knowing it in advance.
+// Sized tells whether Root is the size commitment of a tree built
+// WithSizeCommitment, which is then verified same as VerifySizedAt does.
+type Bundle struct {
+	Algo  string
+	Mode  Mode
+	Leaf  []byte
+	Root  []byte
+	Proof [][]byte
+	Index int
+	Size  int
+	Sized bool
+}
+
+// bundleJSON is the JSON representation of a Bundle.
+type bundleJSON struct {
+	Algo  string   `json:"algo,omitempty"`
+	Mode  Mode     `json:"mode"`
+	Leaf  string   `json:"leaf"`
+	Root  string   `json:"root"`
+	Proof []string `json:"proof"`
+	Index int      `json:"index"`
+	Size  int      `json:"size"`
+	Sized bool     `json:"sized,omitempty"`
+}
+
+// ProveBundle builds the merkle proof for the provided hashed leaf and
+// bundles it up with the leaf itself and the tree merkle root.
+// Returns ErrLeafNotFound if the leaf is not part of the tree.
+func (t Tree) ProveBundle(hl []byte) (*Bundle, error) {
+	proof, i, ok := t.ProofWithIndex(hl)
+	if !ok {
+		return nil, ErrLeafNotFound
+	}
+	return &Bundle{
+		Algo:  t.AlgoName(),
+		Mode:  t.c.mode,
+		Leaf:  hl,
+		Root:  t.publishedRoot(),
+		Proof: proof.ToByteArrays(),
+		Index: i,
+		Size:  len(t.leaves),
+		Sized: t.sized != nil,
+	}, nil
+}
+
+// VerifyBundle verifies whether the proof in the provided Bundle is valid.
+// Bundles naming a known hashing algorithm other than algo are invalid.
+func VerifyBundle(algo hash.Hash, b *Bundle) bool {
+	if !sameAlgo(b.Algo, algo) {
+		return false
+	}
+	if b.Sized {
+		return VerifySizedAt(algo, b.Leaf, b.Root, b.Index, b.Size, b.Proof, WithMode(b.Mode))
+	}
+	return VerifyMode(algo, b.Mode, b.Leaf, b.Root, b.Proof, b.Index, b.Size)
+}
+
+// VerifyBundleBytes unmarshals the provided JSON encoded Bundle and verifies
+// whether its proof is valid against the trusted root, hashing with the
+// algorithm it names rather than a caller supplied one. The root the bundle
+// carries is never trusted on its own, bundles whose root differs are invalid.
+//
+// The named algorithm must be one of allowed or, if none is provided, one
+// of sha224, sha256, sha384, sha512 and keccak256, otherwise it returns
+// ErrHashNotAllowed. It returns ErrUnknownHash if the algorithm is not
+// registered, see RegisterHash.
+func VerifyBundleBytes(data, root []byte, allowed ...string) (bool, error) {
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return false, err
+	}
+	if len(allowed) == 0 {
+		allowed = defaultAllowedHashes
+	}
+	if !containsString(allowed, b.Algo) {
+		return false, fmt.Errorf("%w: %q", ErrHashNotAllowed, b.Algo)
+	}
+	if !bytes.Equal(b.Root, root) {
+		return false, nil
+	}
+	algo, err := HashByName(b.Algo)
+	if err != nil {
+		return false, fmt.Errorf("%w: %q", err, b.Algo)
+	}
+	return VerifyBundle(algo, &b), nil
+}
+
+// containsString tells whether s is one of ss.
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (b Bundle) MarshalJSON() ([]byte, error) {
+	proof := make([]string, 0, len(b.Proof))
+	for _, p := range b.Proof {
+		proof = append(proof, hex.EncodeToString(p))
+	}
+	return json.Marshal(bundleJSON{
+		Algo:  b.Algo,
+		Mode:  b.Mode,
+		Leaf:  hex.EncodeToString(b.Leaf),
+		Root:  hex.EncodeToString(b.Root),
+		Proof: proof,
+		Index: b.Index,
+		Size:  b.Size,
+		Sized: b.Sized,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (b *Bundle) UnmarshalJSON(data []byte) error {
+	var bj bundleJSON
+	if err := json.Unmarshal(data, &bj); err != nil {
+		return err
+	}
+	leaf, err := hex.DecodeString(bj.Leaf)
+	if err != nil {
+		return err
+	}
+	root, err := hex.DecodeString(bj.Root)
+	if err != nil {
+		return err
+	}
+	proof := make([][]byte, 0, len(bj.Proof))
+	for _, p := range bj.Proof {
+		h, err := hex.DecodeString(p)
+		if err != nil {
+			return err
+		}
+		proof = append(proof, h)
+	}
+	b.Algo, b.Mode, b.Leaf, b.Root, b.Proof = bj.Algo, bj.Mode, leaf, root, proof
+	b.Index, b.Size, b.Sized = bj.Index, bj.Size, bj.Sized
+	return nil
+}
@@ -0,0 +1,138 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestTree_ProveBundle(t *testing.T) {
+	t.Run("With Non Existent Leaf", func(t *testing.T) {
+		t.Run("Should Return ErrLeafNotFound", func(t *testing.T) {
+			if _, err := oddLeavesTree.ProveBundle([]byte("foo")); err != ErrLeafNotFound {
+				t.Errorf("expected ErrLeafNotFound, got %v", err)
+			}
+		})
+	})
+	for leaf, expProof := range oddLeavesTreeProofs {
+		t.Run("Should Bundle Leaf, Root And Proof For Leaf "+leaf, func(t *testing.T) {
+			leafb, _ := hex.DecodeString(leaf)
+			b, err := oddLeavesTree.ProveBundle(leafb)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !bytes.Equal(b.Leaf, leafb) || !bytes.Equal(b.Root, oddLeavesTree.root.val) {
+				t.Errorf("unexpected leaf %x or root %x", b.Leaf, b.Root)
+			}
+			if len(b.Proof) != len(expProof) {
+				t.Errorf("expected length of proof to be %d, got %d", len(expProof), len(b.Proof))
+			}
+			if !VerifyBundle(algo, b) {
+				t.Errorf("bundle should have been valid")
+			}
+		})
+	}
+}
+
+func TestBundle_JSON(t *testing.T) {
+	leaf := oddLeavesTree.leaves[0].val
+	b, _ := oddLeavesTree.ProveBundle(leaf)
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	t.Run("Should Encode Hashes As Hex", func(t *testing.T) {
+		exp := `{"algo":"sha256","mode":"sorted","leaf":"18ac3e7343f016890c510e93f935261169d9e3f565436429830faf0934f4f8e4",` +
+			`"root":"3a64c13ffc8d22739538f49d901d909754e4ca185cf128ce7e64c8482f0cd8c6",` +
+			`"proof":["2e7d2c03a9507ae265ecf5b5356885a53393a2029d241394997265a1a25aefc6",` +
+			`"28b5a66c8c61ee13ad5f708a561d758b24d10abe5a0e72133c85d59821539e05",` +
+			`"ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"],"index":0,"size":5}`
+		if act := string(data); act != exp {
+			t.Errorf("expected json to be %s, got %s", exp, act)
+		}
+	})
+
+	t.Run("Should Round Trip", func(t *testing.T) {
+		var act Bundle
+		if err := json.Unmarshal(data, &act); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if !VerifyBundle(algo, &act) {
+			t.Errorf("unmarshalled bundle should have been valid")
+		}
+	})
+
+	t.Run("Should Fail With Bad Hex", func(t *testing.T) {
+		var act Bundle
+		if err := json.Unmarshal([]byte(`{"mode":"sorted","leaf":"zz","root":"","proof":[]}`), &act); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+
+	t.Run("Should Fail With Unknown Mode", func(t *testing.T) {
+		var act Bundle
+		if err := json.Unmarshal([]byte(`{"mode":"foo","leaf":"","root":"","proof":[]}`), &act); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+}
+
+func TestVerifyBundleBytes(t *testing.T) {
+	b, _ := oddLeavesTree.ProveBundle(oddLeavesTree.leaves[0].val)
+	root := oddLeavesTree.Root().Bytes()
+
+	t.Run("Should Verify Without A Caller Supplied Hash", func(t *testing.T) {
+		data, _ := json.Marshal(b)
+		ok, err := VerifyBundleBytes(data, root)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if !ok {
+			t.Errorf("bundle should have been valid")
+		}
+	})
+
+	t.Run("Should Not Verify A Forged Bundle", func(t *testing.T) {
+		forged := *b
+		forged.Root = evenLeavesTree.root.val
+		data, _ := json.Marshal(forged)
+		if ok, err := VerifyBundleBytes(data, root); ok || err != nil {
+			t.Errorf("bundle should have been invalid, got error %v", err)
+		}
+	})
+
+	t.Run("Should Not Verify A Bundle Against Its Own Root", func(t *testing.T) {
+		tree := NewTree(algo, hashStringSlice(algo, "x", "y", "z"))
+		other, _ := tree.ProveBundle(tree.leaves[0].val)
+		data, _ := json.Marshal(other)
+		if ok, err := VerifyBundleBytes(data, root); ok || err != nil {
+			t.Errorf("bundle should have been invalid against the trusted root, got error %v", err)
+		}
+	})
+
+	t.Run("With Disallowed Algorithm Should Return ErrHashNotAllowed", func(t *testing.T) {
+		tree := NewTree(sha1.New(), hashStringSlice(sha1.New(), "x", "y", "z"))
+		weak, _ := tree.ProveBundle(tree.leaves[0].val)
+		data, _ := json.Marshal(weak)
+		if _, err := VerifyBundleBytes(data, tree.Root().Bytes()); !errors.Is(err, ErrHashNotAllowed) {
+			t.Errorf("expected ErrHashNotAllowed, got %v", err)
+		}
+		if ok, err := VerifyBundleBytes(data, tree.Root().Bytes(), "sha1"); !ok || err != nil {
+			t.Errorf("bundle should have been valid once allowed, got %t, %v", ok, err)
+		}
+	})
+
+	t.Run("With Unknown Algorithm Should Return ErrUnknownHash", func(t *testing.T) {
+		unknown := *b
+		unknown.Algo = "foo"
+		data, _ := json.Marshal(unknown)
+		if _, err := VerifyBundleBytes(data, root, "foo"); !errors.Is(err, ErrUnknownHash) {
+			t.Errorf("expected ErrUnknownHash, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package merkle
+
+import (
+	"encoding/binary"
+)
+
+// canonicalVersion is the version of the encoding Canonical makes, which is
+// bumped whenever the encoding changes so that signatures over older
+// encodings can still be told apart rather than silently mismatching.
+const canonicalVersion byte = 2
+
+// Canonical encodes the Proof deterministically, so that equal proofs always
+// make the very same bytes, suiting signers and transcript hashes. Every
+// field Verify depends on is encoded, hence proofs verifying differently
+// never encode the same.
+// The encoding, all numbers being big endian, is made of:
+//
+//   - the version byte, 2
+//   - the mode byte
+//   - the size commitment byte, 1 if the root is a size commitment
+//   - the hashing algorithm name length as an uint16, followed by the name
+//   - the hash size as an uint16
+//   - the leaf index and the tree size as uint64s
+//   - the leaf and the root
+//   - the number of steps as an uint32
+//   - each step as a direction byte followed by the sibling hash
+//
+// The direction byte is 1 when the sibling is the left operand, as derived
+// from the leaf index in positional modes, and always 0 in ModeSorted whose
+// pairs are sorted instead. It returns nil if the leaf, the root and the
+// steps are not all hashes of the same size.
+func (p Proof) Canonical() []byte {
+	size := len(p.leaf)
+	if len(p.root) != size || size > 0xffff || len(p.algo) > 0xffff {
+		return nil
+	}
+	for _, s := range p.steps {
+		if len(s.val) != size {
+			return nil
+		}
+	}
+
+	b := make([]byte, 0, 27+len(p.algo)+2*size+(size+1)*len(p.steps))
+	var n [8]byte
+	sized := byte(0)
+	if p.sized {
+		sized = 1
+	}
+	b = append(b, canonicalVersion, byte(p.mode), sized)
+	binary.BigEndian.PutUint16(n[:2], uint16(len(p.algo)))
+	b = append(append(b, n[:2]...), p.algo...)
+	binary.BigEndian.PutUint16(n[:2], uint16(size))
+	b = append(b, n[:2]...)
+	binary.BigEndian.PutUint64(n[:], uint64(p.index))
+	b = append(b, n[:]...)
+	binary.BigEndian.PutUint64(n[:], uint64(p.size))
+	b = append(b, n[:]...)
+	b = append(append(b, p.leaf...), p.root...)
+	binary.BigEndian.PutUint32(n[:4], uint32(len(p.steps)))
+	b = append(b, n[:4]...)
+	for i, left := range p.directions() {
+		dir := byte(0)
+		if left {
+			dir = 1
+		}
+		b = append(append(b, dir), p.steps[i].val...)
+	}
+	return b
+}
+
+// directions tells, for each step, whether the sibling is the left operand
+// when hashing the pair, deriving it from the leaf index same as
+// reconstructAt does. Siblings of sorted proofs are never the left one.
+func (p Proof) directions() []bool {
+	dirs := make([]bool, len(p.steps))
+	c := newConfig(WithMode(p.mode))
+	if !c.mode.positional() {
+		return dirs
+	}
+	k, index := 0, p.index
+	for n := p.size; n > 1 && k < len(dirs); n = (n + 1) / 2 {
+		// a node at the end of an odd level is either promoted
+		// without any step or it's paired with itself.
+		if index%2 == 1 || index+1 < n || c.duplicateOdd {
+			dirs[k] = index%2 == 1
+			k++
+		}
+		index /= 2
+	}
+	return dirs
+}
@@ -0,0 +1,70 @@
+package merkle
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestProof_Canonical(t *testing.T) {
+	tree := NewTree(algo, hashStringSlice(algo, "a", "b", "c", "d", "e"), WithMode(ModeOrdered))
+
+	t.Run("Should Encode Header, Leaf, Root And Steps", func(t *testing.T) {
+		p, _ := tree.ProofOf(tree.leaves[3].val)
+		b := p.Canonical()
+		if exp := 27 + len("sha256") + 2*32 + 33*p.Len(); len(b) != exp {
+			t.Fatalf("expected %d bytes, got %d", exp, len(b))
+		}
+		if exp := append([]byte{canonicalVersion, byte(ModeOrdered), 0, 0, 6}, "sha256\x00\x20"...); !bytes.Equal(b[:13], exp) {
+			t.Errorf("unexpected header %x", b[:13])
+		}
+		// leaf 3 is a right child, then its parent is a right child too.
+		steps := b[len(b)-33*p.Len():]
+		for i, exp := range []byte{1, 1, 0} {
+			if steps[i*33] != exp {
+				t.Errorf("expected direction of step %d to be %d, got %d", i, exp, steps[i*33])
+			}
+		}
+	})
+
+	t.Run("Should Be Deterministic", func(t *testing.T) {
+		a, _ := tree.ProofOf(tree.leaves[1].val)
+		b, _ := tree.ProofOf(tree.leaves[1].val)
+		if !bytes.Equal(a.Canonical(), b.Canonical()) {
+			t.Errorf("expected equal proofs to encode the same")
+		}
+		c, _ := tree.ProofOf(tree.leaves[2].val)
+		if bytes.Equal(a.Canonical(), c.Canonical()) {
+			t.Errorf("expected different proofs to encode differently")
+		}
+	})
+
+	t.Run("Should Encode Algorithm And Size Commitment", func(t *testing.T) {
+		p, _ := tree.ProofOf(tree.leaves[1].val)
+		named, sized := *p, *p
+		named.algo = "sha-256"
+		sized.sized = true
+		for _, q := range []Proof{named, sized} {
+			if bytes.Equal(p.Canonical(), q.Canonical()) {
+				t.Errorf("expected proofs of algorithm %q, size committed %t, to encode differently", q.algo, q.sized)
+			}
+		}
+	})
+
+	t.Run("Should Not Set Directions In Sorted Mode", func(t *testing.T) {
+		p, _ := oddLeavesTree.ProofOf(oddLeavesTree.leaves[4].val)
+		b := p.Canonical()
+		steps := b[len(b)-33*p.Len():]
+		for i := 0; i < p.Len(); i++ {
+			if steps[i*33] != 0 {
+				t.Errorf("expected direction of step %d to be 0", i)
+			}
+		}
+	})
+
+	t.Run("With Mismatching Hash Sizes Should Return Nil", func(t *testing.T) {
+		p := NewProof([]byte("foo"), oddLeavesTree.root.val, nil)
+		if b := p.Canonical(); b != nil {
+			t.Errorf("expected nil, got %x", b)
+		}
+	})
+}
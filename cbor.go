@@ -0,0 +1,188 @@
+package merkle
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrCBORFormat is returned when unmarshalling a malformed CBOR Proof.
+var ErrCBORFormat = errors.New("merkle: malformed CBOR proof")
+
+// CBOR major types used to encode a Proof.
+const (
+	cborUint   byte = 0
+	cborBytes  byte = 2
+	cborText   byte = 3
+	cborArray  byte = 4
+	cborSimple byte = 7
+)
+
+// CBOR simple values encoding booleans.
+const (
+	cborFalse byte = 20
+	cborTrue  byte = 21
+)
+
+// MarshalCBOR encodes the Proof as a CBOR array made of the mode, the leaf,
+// the root, the array of steps, the leaf index, the tree size, whether
+// the root is a size commitment and the hashing algorithm name, in this
+// order, hashes being byte strings, numbers unsigned integers, the size
+// commitment flag a boolean and the name a text string, empty if unknown.
+// Being the leaf index enough to tell left siblings from right ones in
+// positional modes, no direction bit is encoded alongside the steps.
+//
+// It's far more compact than JSON, suiting constrained environments.
+func (p Proof) MarshalCBOR() ([]byte, error) {
+	b := appendCBORHead(nil, cborArray, 8)
+	b = appendCBORHead(b, cborUint, uint64(p.mode))
+	b = appendCBORBytes(b, p.leaf)
+	b = appendCBORBytes(b, p.root)
+	b = appendCBORHead(b, cborArray, uint64(len(p.steps)))
+	for _, s := range p.steps {
+		b = appendCBORBytes(b, s.val)
+	}
+	b = appendCBORHead(b, cborUint, uint64(p.index))
+	b = appendCBORHead(b, cborUint, uint64(p.size))
+	b = appendCBORBool(b, p.sized)
+	b = append(appendCBORHead(b, cborText, uint64(len(p.algo))), p.algo...)
+	return b, nil
+}
+
+// UnmarshalCBOR decodes the Proof from the CBOR encoding MarshalCBOR makes.
+// It returns ErrCBORFormat if data is malformed.
+func (p *Proof) UnmarshalCBOR(data []byte) error {
+	d := cborDecoder{b: data}
+	if n := d.head(cborArray); n != 8 {
+		return ErrCBORFormat
+	}
+	mode := d.head(cborUint)
+	leaf, root := d.bytes(), d.bytes()
+	n := d.head(cborArray)
+	// every step takes at least a byte, which bounds n before allocating.
+	if n > uint64(len(d.b)) {
+		return ErrCBORFormat
+	}
+	steps := make(Nodes, 0, n)
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		steps = append(steps, newNode(d.bytes()))
+	}
+	index, size := d.head(cborUint), d.head(cborUint)
+	sized, algo := d.bool(), d.text()
+	if d.err != nil || len(d.b) > 0 {
+		return ErrCBORFormat
+	}
+	p.leaf, p.root, p.steps = leaf, root, steps
+	p.mode, p.index, p.size, p.sized, p.algo = Mode(mode), int(index), int(size), sized, algo
+	return nil
+}
+
+// appendCBORHead appends the head of a CBOR data item
+// of the provided major type and argument n to b.
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	major <<= 5
+	if n < 24 {
+		return append(b, major|byte(n))
+	}
+	// the argument follows big endian in the least of 1, 2, 4 or 8 bytes.
+	var arg [8]byte
+	binary.BigEndian.PutUint64(arg[:], n)
+	switch {
+	case n <= 0xff:
+		return append(append(b, major|24), arg[7:]...)
+	case n <= 0xffff:
+		return append(append(b, major|25), arg[6:]...)
+	case n <= 0xffffffff:
+		return append(append(b, major|26), arg[4:]...)
+	}
+	return append(append(b, major|27), arg[:]...)
+}
+
+// appendCBORBytes appends v as a CBOR byte string to b.
+func appendCBORBytes(b, v []byte) []byte {
+	return append(appendCBORHead(b, cborBytes, uint64(len(v))), v...)
+}
+
+// appendCBORBool appends v as a CBOR boolean to b.
+func appendCBORBool(b []byte, v bool) []byte {
+	if v {
+		return appendCBORHead(b, cborSimple, uint64(cborTrue))
+	}
+	return appendCBORHead(b, cborSimple, uint64(cborFalse))
+}
+
+// cborDecoder decodes CBOR data items one after the other,
+// the first error met is kept and any further decoding is a no-op.
+type cborDecoder struct {
+	b   []byte
+	err error
+}
+
+// head decodes the head of a data item of the provided major type,
+// returning its argument.
+func (d *cborDecoder) head(major byte) uint64 {
+	if d.err != nil || len(d.b) == 0 || d.b[0]>>5 != major {
+		d.err = ErrCBORFormat
+		return 0
+	}
+	info := d.b[0] & 0x1f
+	d.b = d.b[1:]
+	if info < 24 {
+		return uint64(info)
+	}
+	size := 0
+	switch info {
+	case 24:
+		size = 1
+	case 25:
+		size = 2
+	case 26:
+		size = 4
+	case 27:
+		size = 8
+	}
+	if size == 0 || len(d.b) < size {
+		d.err = ErrCBORFormat
+		return 0
+	}
+	n := uint64(0)
+	for _, x := range d.b[:size] {
+		n = n<<8 | uint64(x)
+	}
+	d.b = d.b[size:]
+	return n
+}
+
+// bytes decodes a byte string.
+func (d *cborDecoder) bytes() []byte {
+	return d.string(cborBytes)
+}
+
+// text decodes a text string.
+func (d *cborDecoder) text() string {
+	return string(d.string(cborText))
+}
+
+// string decodes a string of the provided major type.
+func (d *cborDecoder) string(major byte) []byte {
+	n := d.head(major)
+	if d.err != nil || n > uint64(len(d.b)) {
+		d.err = ErrCBORFormat
+		return nil
+	}
+	v := d.b[:n:n]
+	d.b = d.b[n:]
+	return v
+}
+
+// bool decodes a boolean.
+func (d *cborDecoder) bool() bool {
+	switch v := d.head(cborSimple); {
+	case d.err != nil:
+		return false
+	case v == uint64(cborTrue):
+		return true
+	case v != uint64(cborFalse):
+		d.err = ErrCBORFormat
+	}
+	return false
+}
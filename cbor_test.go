@@ -0,0 +1,78 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+)
+
+func TestProof_MarshalCBOR(t *testing.T) {
+	t.Run("Should Encode Expected Bytes", func(t *testing.T) {
+		p := &Proof{leaf: []byte{1}, root: []byte{2}, steps: NodesFromBytes([]byte{3}), mode: ModeRFC6962, index: 1, size: 300}
+		b, _ := p.MarshalCBOR()
+		// [2, h'01', h'02', [h'03'], 1, 300, false, ""]
+		if exp, act := "8802410141028141030119012cf460", hex.EncodeToString(b); act != exp {
+			t.Errorf("expected %s, got %s", exp, act)
+		}
+	})
+
+	for _, m := range []Mode{ModeSorted, ModeRFC6962} {
+		tree := NewTree(algo, hashStringSlice(algo, "a", "b", "c", "d", "e"), WithMode(m))
+		for _, l := range tree.leaves {
+			p, _ := tree.ProofOf(l.val)
+			b, err := p.MarshalCBOR()
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			t.Run("Should Round Trip "+m.String()+" Proof For "+l.Hex(), func(t *testing.T) {
+				var act Proof
+				if err := act.UnmarshalCBOR(b); err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				if !bytes.Equal(act.Leaf(), p.Leaf()) || !bytes.Equal(act.Root(), p.Root()) || act.Len() != p.Len() {
+					t.Errorf("expected proof to round trip")
+				}
+				if !act.Verify(algo) {
+					t.Errorf("proof should have been valid")
+				}
+			})
+			t.Run("Should Return ErrCBORFormat For Truncated "+m.String()+" Proof For "+l.Hex(), func(t *testing.T) {
+				var act Proof
+				for i := 0; i < len(b); i++ {
+					if err := act.UnmarshalCBOR(b[:i]); err != ErrCBORFormat {
+						t.Fatalf("expected ErrCBORFormat truncating at %d, got %v", i, err)
+					}
+				}
+			})
+		}
+	}
+
+	t.Run("Should Round Trip Size Committed And Named Proofs", func(t *testing.T) {
+		h := sha512.New()
+		hl := hashStringSlice(h, "a", "b", "c", "d", "e")
+		for _, tree := range []*Tree{
+			NewTree(h, hl, WithMode(ModeRFC6962), WithSizeCommitment()),
+			NewTreeNamed("sha-512", h, hl, WithMode(ModeRFC6962)),
+		} {
+			p, err := tree.ProofOf(tree.leaves[2].val)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			b, _ := p.MarshalCBOR()
+			var act Proof
+			if err := act.UnmarshalCBOR(b); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if act.AlgoName() != p.AlgoName() || act.sized != p.sized {
+				t.Errorf("expected algorithm %q and size commitment %t to round trip, got %q and %t", p.AlgoName(), p.sized, act.AlgoName(), act.sized)
+			}
+			if !act.Verify(h) {
+				t.Errorf("proof should have been valid")
+			}
+			if act.Verify(algo) {
+				t.Errorf("proof should have been invalid with another algorithm")
+			}
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package merkle
+
+import "sync"
+
+// Compact drops the inner Nodes of the tree, keeping only its sorted
+// leaves and its root hash, which is all Contains, BoundRoot and Verify
+// need, thus reclaiming roughly half of the memory the tree takes.
+//
+// Compact trades the cost of future proofs for immediate memory savings,
+// as the inner Nodes are rebuilt from the leaves, same as a LeanTree does
+// for each proof, the first time anything needs them again, e.g. Proof,
+// Walk, Update or Root, whose Node exposes its children. Such rebuild
+// costs O(n) hashing once, holding the WithHashLock lock if any, and it's
+// safe for concurrent use. Compact itself is not, same as Insert.
+// It has no effect on trees built WithoutLeaves or of a single leaf.
+func (t *Tree) Compact() {
+	if t.c.withoutLeaves || len(t.leaves) < 2 {
+		return
+	}
+	for _, l := range t.leaves {
+		l.parent = nil
+	}
+	t.root.left, t.root.right = nil, nil
+	t.compacted = new(sync.Once)
+}
+
+// expand rebuilds the inner Nodes of a tree that was compacted, if any,
+// grafting them onto the current root so that its *Node stays the same.
+// It holds the hash lock, thus it must be called before taking it.
+func (t Tree) expand() {
+	if t.compacted == nil {
+		return
+	}
+	t.compacted.Do(func() {
+		defer t.c.lock()()
+		root := buildTree(t.h, t.leaves, nil, t.c, true, 0)
+		t.root.left, t.root.right = root.left, root.right
+		t.root.left.parent, t.root.right.parent = t.root, t.root
+	})
+}
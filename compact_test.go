@@ -0,0 +1,96 @@
+package merkle
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestTree_Compact(t *testing.T) {
+	for _, mode := range []Mode{ModeSorted, ModeBitcoin, ModeRFC6962, ModeOrdered, ModeUnsorted} {
+		t.Run("Should Keep Root And Proofs For Mode "+mode.String(), func(t *testing.T) {
+			hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+			tree := NewTree(algo, hl, WithMode(mode))
+			root := tree.Root()
+			expected := tree.Root().String()
+			proof := tree.Proof(hl[2]).ToHexStrings()
+
+			tree.Compact()
+			if root.left != nil || tree.leaves[0].parent != nil {
+				t.Fatalf("expected inner nodes to be dropped")
+			}
+			if act := tree.Root().String(); act != expected {
+				t.Errorf("expected merkle root to be %s, got %s", expected, act)
+			}
+			if !tree.Contains(hl[2]) {
+				t.Errorf("expected leaf to be contained")
+			}
+
+			act := tree.Proof(hl[2]).ToHexStrings()
+			if len(act) != len(proof) {
+				t.Fatalf("expected proof length to be %d, got %d", len(proof), len(act))
+			}
+			for i := range proof {
+				if act[i] != proof[i] {
+					t.Errorf("expected proof node at index %d to be %s, got %s", i, proof[i], act[i])
+				}
+			}
+			if tree.Root() != root {
+				t.Errorf("expected the root *Node to be kept")
+			}
+			verifyAllModeProofs(t, tree)
+		})
+	}
+
+	t.Run("Should Rebuild Root Children", func(t *testing.T) {
+		hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+		tree := NewTree(algo, hl)
+		tree.Compact()
+		if tree.Root().IsLeaf() {
+			t.Errorf("expected the root not to be a leaf")
+		}
+		if act := len(tree.Root().Leaves()); act != len(hl) {
+			t.Errorf("expected %d leaves, got %d", len(hl), act)
+		}
+	})
+
+	t.Run("Should Rebuild Once Concurrently", func(t *testing.T) {
+		hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+		tree := NewTree(algo, hl)
+		expected := tree.Proof(hl[0]).ToHexStrings()
+		tree.Compact()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if act := tree.Proof(hl[0]).ToHexStrings(); len(act) != len(expected) {
+					t.Errorf("expected proof length to be %d, got %d", len(expected), len(act))
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("Should Rebuild On Update", func(t *testing.T) {
+		hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+		tree := NewTree(algo, hl)
+		tree.Compact()
+		if err := tree.Update(hl[0], hashStringSlice(algo, "f")[0]); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		expected := NewTree(algo, hashStringSlice(algo, "f", "b", "c", "d", "e")).Root().String()
+		if act := tree.Root().String(); act != expected {
+			t.Errorf("expected merkle root to be %s, got %s", expected, act)
+		}
+	})
+
+	t.Run("Should Have No Effect On Single Leaf Tree", func(t *testing.T) {
+		hl := hashStringSlice(algo, "a")
+		tree := NewTree(algo, hl)
+		tree.Compact()
+		if act := tree.Root().Bytes(); string(act) != string(hl[0]) {
+			t.Errorf("expected merkle root to be the leaf")
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"hash"
+)
+
+// ErrTreeSize is returned when the provided tree size is out of range.
+var ErrTreeSize = errors.New("merkle: tree size out of range")
+
+// ConsistencyProof builds and returns the RFC 6962 consistency proof
+// between the tree made of the first oldSize leaves and the whole tree,
+// proving the former is a prefix of the latter, that is, the log has
+// only been appended to.
+//
+// It's supported by modes keeping leaves in the provided order and
+// promoting odd nodes, that is, ModeRFC6962 and ModeOrdered, for any other
+// Mode ErrModeUnsupported is returned. ErrTreeSize is returned if oldSize
+// is either lesser than 1 or greater than the number of leaves.
+func (t Tree) ConsistencyProof(oldSize int) ([][]byte, error) {
+	if !t.c.mode.positional() || t.c.mode.sortsLeaves() || t.c.duplicateOdd {
+		return nil, ErrModeUnsupported
+	}
+	if t.c.withoutLeaves {
+		return nil, ErrNoLeaves
+	}
+	if oldSize < 1 || oldSize > len(t.leaves) {
+		return nil, ErrTreeSize
+	}
+	defer t.c.lock()()
+	return t.subproof(oldSize, t.leaves.ToByteArrays(), true), nil
+}
+
+// subproof is the SUBPROOF of RFC 6962 section 2.1.2 proving
+// the first m leaves are a prefix of the provided leaves,
+// complete tells whether the m leaves make a complete subtree.
+func (t Tree) subproof(m int, leaves [][]byte, complete bool) [][]byte {
+	n := len(leaves)
+	if m == n {
+		if complete {
+			return nil
+		}
+		return [][]byte{foldLeaves(t.h, leaves, t.c)}
+	}
+	// k is the largest power of 2 smaller than n.
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	if m <= k {
+		return append(t.subproof(m, leaves[:k], complete), foldLeaves(t.h, leaves[k:], t.c))
+	}
+	return append(t.subproof(m-k, leaves[k:], false), foldLeaves(t.h, leaves[:k], t.c))
+}
+
+// VerifyConsistency verifies whether the provided RFC 6962 consistency
+// proof is valid, that is, the tree of oldSize leaves with oldRoot
+// is a prefix of the tree of newSize leaves with newRoot.
+// The hashing of pairs can be customised with Option(s), by
+// default they're hashed same as ModeRFC6962 does.
+func VerifyConsistency(algo hash.Hash, oldSize, newSize int, oldRoot, newRoot []byte, proof [][]byte, opts ...Option) bool {
+	c := newConfig(append([]Option{WithMode(ModeRFC6962)}, opts...)...)
+	if oldSize < 1 || oldSize > newSize {
+		return false
+	}
+	if oldSize == newSize {
+		return len(proof) == 0 && bytes.Equal(oldRoot, newRoot)
+	}
+
+	// following RFC 9162 section 2.1.4.2.
+	if oldSize&(oldSize-1) == 0 {
+		// the old tree is a complete subtree, which is
+		// left out of the proof being its root known.
+		proof = append([][]byte{oldRoot}, proof...)
+	}
+	if len(proof) == 0 {
+		return false
+	}
+	fn, sn := oldSize-1, newSize-1
+	for fn&1 == 1 {
+		fn, sn = fn>>1, sn>>1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, p := range proof[1:] {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = c.combine(algo, p, fr, 0)
+			sr = c.combine(algo, p, sr, 0)
+			for fn&1 == 0 && fn != 0 {
+				fn, sn = fn>>1, sn>>1
+			}
+		} else {
+			sr = c.combine(algo, sr, p, 0)
+		}
+		fn, sn = fn>>1, sn>>1
+	}
+	return sn == 0 && bytes.Equal(fr, oldRoot) && bytes.Equal(sr, newRoot)
+}
+
+// VerifyInclusionAtSize verifies whether leaf, at the provided index, was
+// included in the tree of oldSize leaves and that such tree is consistent
+// with the tree of newSize leaves with newRoot. The old root is
+// reconstructed from the inclusion proof and then proven to be a prefix
+// of the new tree with the consistency proof, which is the standard
+// transparency log client verification of proofs issued at an older size.
+// The hashing of pairs can be customised with Option(s), by
+// default they're hashed same as ModeRFC6962 does.
+func VerifyInclusionAtSize(algo hash.Hash, leaf []byte, index, oldSize, newSize int, newRoot []byte, inclusionProof, consistencyProof [][]byte, opts ...Option) bool {
+	c := newConfig(append([]Option{WithMode(ModeRFC6962)}, opts...)...)
+	oldRoot, ok := c.reconstructAt(algo, leaf, inclusionProof, index, oldSize)
+	if !ok {
+		return false
+	}
+	return VerifyConsistency(algo, oldSize, newSize, oldRoot, newRoot, consistencyProof, opts...)
+}
+
+// AuditPath builds and returns the RFC 6962 audit path, that is, the
+// inclusion proof, of the leaf at the provided index. Siblings are listed
+// bottom-up as found in the tree, their orientation being implied by the
+// index, same as Certificate Transparency clients expect them.
+//
+// It's supported by ModeRFC6962 and ModeOrdered, for any other Mode
+// ErrModeUnsupported is returned. ErrLeafNotFound is returned if there's
+// no leaf at the provided index.
+func (t Tree) AuditPath(index int) ([][]byte, error) {
+	if !t.c.mode.positional() || t.c.mode.sortsLeaves() || t.c.duplicateOdd {
+		return nil, ErrModeUnsupported
+	}
+	if t.c.withoutLeaves {
+		return nil, ErrNoLeaves
+	}
+	if index < 0 || index >= len(t.leaves) {
+		return nil, ErrLeafNotFound
+	}
+	return t.proofAt(index, make(Nodes, 0, height(len(t.leaves)))).ToByteArrays(), nil
+}
+
+// VerifyAuditPath verifies whether the provided RFC 6962 audit path proves
+// the leaf hash, at the provided index, is included in the tree of treeSize
+// leaves with the provided root, following RFC 9162 section 2.1.3.2.
+// The leaf hash is the one of the leaf data, i.e. H(0x00 || data).
+func VerifyAuditPath(algo hash.Hash, leafHash []byte, index, treeSize int, root []byte, path [][]byte) bool {
+	return newConfig(WithMode(ModeRFC6962)).verifyAt(algo, leafHash, root, path, index, treeSize)
+}
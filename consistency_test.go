@@ -0,0 +1,139 @@
+package merkle
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestTree_ConsistencyProof(t *testing.T) {
+	data := make([][]byte, 20)
+	for i := range data {
+		data[i] = []byte(strconv.Itoa(i))
+	}
+
+	for n := 1; n <= len(data); n++ {
+		tree := NewTreeFromData(algo, data[:n], WithMode(ModeRFC6962))
+		newRoot := tree.Root().Bytes()
+		for m := 1; m <= n; m++ {
+			old := NewTreeFromData(algo, data[:m], WithMode(ModeRFC6962))
+			oldRoot := old.Root().Bytes()
+			proof, err := tree.ConsistencyProof(m)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !VerifyConsistency(algo, m, n, oldRoot, newRoot, proof) {
+				t.Errorf("consistency proof from %d to %d leaves should have been valid", m, n)
+			}
+			if m < n && VerifyConsistency(algo, m, n, newRoot, newRoot, proof) {
+				t.Errorf("consistency proof from %d to %d leaves should have been invalid for wrong root", m, n)
+			}
+
+			// proofs issued at the old size along with the consistency proof.
+			for i, l := range old.leaves {
+				inclusion := old.Proof(l.val).ToByteArrays()
+				if !VerifyInclusionAtSize(algo, l.val, i, m, n, newRoot, inclusion, proof) {
+					t.Errorf("inclusion of leaf %d at %d leaves should have been valid at %d leaves", i, m, n)
+				}
+			}
+		}
+	}
+
+	t.Run("Should Return ErrModeUnsupported", func(t *testing.T) {
+		for _, m := range []Mode{ModeSorted, ModeBitcoin, ModeUnsorted} {
+			tree := NewTreeFromData(algo, data, WithMode(m))
+			if _, err := tree.ConsistencyProof(2); err != ErrModeUnsupported {
+				t.Errorf("expected ErrModeUnsupported in %s mode, got %v", m, err)
+			}
+		}
+	})
+
+	t.Run("Should Return ErrTreeSize", func(t *testing.T) {
+		tree := NewTreeFromData(algo, data, WithMode(ModeRFC6962))
+		for _, m := range []int{0, len(data) + 1} {
+			if _, err := tree.ConsistencyProof(m); err != ErrTreeSize {
+				t.Errorf("expected ErrTreeSize for %d, got %v", m, err)
+			}
+		}
+	})
+}
+
+func TestTree_AuditPath(t *testing.T) {
+	// Certificate Transparency test vectors.
+	var data [][]byte
+	for _, s := range []string{"", "00", "10", "2021", "3031", "40414243", "5051525354555657", "606162636465666768696a6b6c6d6e6f"} {
+		b, _ := hex.DecodeString(s)
+		data = append(data, b)
+	}
+	vectors := []struct {
+		index, size int
+		root        string
+		path        []string
+	}{
+		{0, 8, "5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328", []string{
+			"96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7",
+			"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+			"6b47aaf29ee3c2af9af889bc1fb9254dabd31177f16232dd6aab035ca39bf6e4",
+		}},
+		{5, 8, "5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328", []string{
+			"bc1a0643b12e4d2d7c77918f44e0f4f79a838b6cf9ec5b5c283e1f4d88599e6b",
+			"ca854ea128ed050b41b35ffc1b87b8eb2bde461e9e3b5596ece6b9d5975a0ae0",
+			"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		}},
+		{2, 3, "aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77", []string{
+			"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+		}},
+		{1, 5, "4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4", []string{
+			"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+			"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+			"bc1a0643b12e4d2d7c77918f44e0f4f79a838b6cf9ec5b5c283e1f4d88599e6b",
+		}},
+	}
+
+	for _, v := range vectors {
+		t.Run(fmt.Sprintf("Should Match Vector For Leaf %d Of %d", v.index, v.size), func(t *testing.T) {
+			tree := NewTreeFromData(algo, data[:v.size], WithMode(ModeRFC6962))
+			if act := tree.Root().String(); act != v.root {
+				t.Fatalf("expected merkle root to be %s, got %s", v.root, act)
+			}
+			path, err := tree.AuditPath(v.index)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if len(path) != len(v.path) {
+				t.Fatalf("expected audit path length to be %d, got %d", len(v.path), len(path))
+			}
+			for i, p := range path {
+				if act := hex.EncodeToString(p); act != v.path[i] {
+					t.Errorf("expected node at index %d to be %s, got %s", i, v.path[i], act)
+				}
+			}
+			leaf := tree.leaves[v.index].val
+			if !VerifyAuditPath(algo, leaf, v.index, v.size, tree.Root().Bytes(), path) {
+				t.Errorf("audit path should have been valid")
+			}
+			if VerifyAuditPath(algo, leaf, v.index^1, v.size, tree.Root().Bytes(), path) {
+				t.Errorf("audit path should have been invalid at a different index")
+			}
+		})
+	}
+
+	t.Run("Should Return ErrModeUnsupported", func(t *testing.T) {
+		for _, m := range []Mode{ModeSorted, ModeBitcoin, ModeUnsorted} {
+			tree := NewTreeFromData(algo, data, WithMode(m))
+			if _, err := tree.AuditPath(0); err != ErrModeUnsupported {
+				t.Errorf("expected ErrModeUnsupported in %s mode, got %v", m, err)
+			}
+		}
+	})
+
+	t.Run("Should Return ErrLeafNotFound", func(t *testing.T) {
+		tree := NewTreeFromData(algo, data, WithMode(ModeRFC6962))
+		for _, i := range []int{-1, len(data)} {
+			if _, err := tree.AuditPath(i); err != ErrLeafNotFound {
+				t.Errorf("expected ErrLeafNotFound for %d, got %v", i, err)
+			}
+		}
+	})
+}
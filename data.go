@@ -0,0 +1,129 @@
+package merkle
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+// NewTreeFromData builds up a new merkle tree same as NewTree but it takes
+// raw leaves data, which is hashed with the provided hashing algorithm
+// before building the tree, applying the WithLeafPrefix Option if any.
+// In ModeBitcoin leaves data is double hashed, same as transactions are.
+// WithIndexedLeaves mixes the index of the data within data into its hash.
+func NewTreeFromData(h hash.Hash, data [][]byte, opts ...Option) *Tree {
+	return NewTreeFromEncoder(h, len(data), func(i int) []byte {
+		return data[i]
+	}, opts...)
+}
+
+// NewTreeFromEncoder builds up a new merkle tree same as NewTreeFromData
+// does out of n records, e.g. structs, whose raw data is encode(i) for the
+// record at index i. Encoding is left to the caller, which avoids both
+// reflection and an intermediate slice of data. Records are encoded once
+// each, in order, and the encoded data is not retained once hashed.
+func NewTreeFromEncoder(h hash.Hash, n int, encode func(i int) []byte, opts ...Option) *Tree {
+	c := newConfig(opts...)
+	leaves := make(Nodes, n)
+	for i := range leaves {
+		data := encode(i)
+		// holding the lock while hashing only, encode is the caller's.
+		unlock := c.lock()
+		leaves[i] = newNode(c.hashLeafAt(h, i, data))
+		unlock()
+	}
+	return c.padTree(h, leaves)
+}
+
+// ProveData hashes the raw leaf data the same way NewTreeFromData does
+// with the tree Option(s), returning the computed leaf hash alongside its
+// merkle proof, so that data oriented callers can later Verify it.
+// If the leaf can't be found ok is false. Trees built WithIndexedLeaves
+// are not supported, as the index of the data can't be told.
+func (t Tree) ProveData(raw []byte) (leafHash []byte, proof Nodes, ok bool) {
+	unlock := t.c.lock()
+	leafHash = t.c.hashLeaf(t.h, raw)
+	unlock()
+	proof, _, ok = t.ProofWithIndex(leafHash)
+	return leafHash, proof, ok
+}
+
+// VerifyData verifies whether the provided proof is valid for
+// the raw leaf data, hashing it the same way NewTreeFromData does
+// with the provided Option(s) before verifying the proof.
+//
+// Same as VerifyWith, it verifies proofs of trees in ModeSorted only, as
+// positional modes need the leaf index and the tree size, thus it returns
+// false for any other Mode. Such proofs are verified hashing the data with
+// HashLeaf and the proof with VerifyMode, or VerifyIndexed if indexed.
+func VerifyData(h hash.Hash, data, root []byte, proof [][]byte, opts ...Option) bool {
+	c := newConfig(opts...)
+	if c.mode.positional() {
+		return false
+	}
+	return VerifyWith(h, c.hashLeaf(h, data), root, proof, opts...)
+}
+
+// LeafOption is an Option affecting how raw leaf values are hashed, that
+// is, WithLeafPrefix for domain separation and WithMode, whose leaves may
+// be hashed differently, e.g. double hashed in ModeBitcoin. Any other
+// Option is accepted as well as it may affect how the proof is verified.
+type LeafOption = Option
+
+// VerifyValue verifies in one call that the proof is valid and that the
+// leaf is the hash of the provided raw value, catching both wrong values
+// and bad proofs. It's the same as VerifyData, thus it returns false for
+// positional modes, e.g. for sorted trees of prefixed leaves :
+//
+//	merkle.VerifyValue(sha256.New(), value, root, proof, merkle.WithLeafPrefix([]byte{0x00}))
+func VerifyValue(h hash.Hash, value, root []byte, proof [][]byte, opts ...LeafOption) bool {
+	return VerifyData(h, value, root, proof, opts...)
+}
+
+// VerifyIndexed verifies whether the provided proof is valid for the raw
+// leaf data at index within the size leaves of a tree built from data
+// WithIndexedLeaves, mixing index into the data hash the same way.
+// Trees are assumed to be built in ModeOrdered, which can be
+// overridden providing a different Mode within the Option(s).
+func VerifyIndexed(algo hash.Hash, index, size int, data, root []byte, proof [][]byte, opts ...Option) bool {
+	c := newConfig(append([]Option{WithMode(ModeOrdered), WithIndexedLeaves()}, opts...)...)
+	return c.verifyAt(algo, c.hashLeafAt(algo, index, data), root, proof, index, size)
+}
+
+// HashLeaf hashes the raw leaf data the same way NewTreeFromData does,
+// applying the provided LeafOption(s), e.g. the 0x00 prefix of RFC 6962
+// leaves WithMode(ModeRFC6962), so that leaves are hashed the same way
+// whether they're being inserted into a tree or verified. Trees built
+// WithIndexedLeaves hash the index as well, see VerifyIndexed.
+func HashLeaf(h hash.Hash, data []byte, opts ...LeafOption) []byte {
+	return newConfig(opts...).hashLeaf(h, data)
+}
+
+// hashLeaf hashes the raw leaf data applying the config leaf prefix.
+func (c *config) hashLeaf(h hash.Hash, data []byte) []byte {
+	return c.hashLeafAt(h, 0, data)
+}
+
+// hashLeafAt hashes the raw leaf data at the provided index applying the
+// config leaf prefix, the index is mixed in only WithIndexedLeaves.
+func (c *config) hashLeafAt(h hash.Hash, index int, data []byte) []byte {
+	h.Reset()
+	if c.lengthPrefix {
+		writeLengthPrefixed(h, c.leafPrefix)
+	} else {
+		h.Write(c.leafPrefix)
+	}
+	if c.indexedLeaves {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(index))
+		h.Write(b[:])
+	}
+	if c.lengthPrefix {
+		writeLengthPrefixed(h, data)
+	} else {
+		h.Write(data)
+	}
+	if c.mode == ModeBitcoin {
+		return rehash(h, h.Sum(nil))
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,189 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestNewTreeFromData(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+
+	t.Run("Should Return Same Root As Hashed Leaves", func(t *testing.T) {
+		exp := oddLeavesTree.Root().String()
+		if act := NewTreeFromData(algo, data).Root().String(); act != exp {
+			t.Errorf("expected merkle root should have been %s, got %s", exp, act)
+		}
+	})
+
+	t.Run("Should Apply Leaf Prefix", func(t *testing.T) {
+		tree := NewTreeFromData(algo, data, WithLeafPrefix([]byte{0}))
+		if act := tree.Root().String(); act == oddLeavesTree.Root().String() {
+			t.Errorf("expected prefixed merkle root to differ, got %s", act)
+		}
+		leaf := hashStringSlice(algo, "\x00c")[0]
+		if _, _, ok := tree.ProofWithIndex(leaf); !ok {
+			t.Errorf("expected prefixed leaf to be found")
+		}
+	})
+}
+
+func TestNewTreeFromEncoder(t *testing.T) {
+	type record struct {
+		id   uint32
+		name string
+	}
+	records := []record{{1, "a"}, {2, "b"}, {3, "c"}}
+	encode := func(i int) []byte {
+		b := make([]byte, 4, 4+len(records[i].name))
+		binary.BigEndian.PutUint32(b, records[i].id)
+		return append(b, records[i].name...)
+	}
+
+	for _, m := range []Mode{ModeSorted, ModeRFC6962} {
+		t.Run("Should Build Same Tree As From Data In "+m.String()+" Mode", func(t *testing.T) {
+			data := make([][]byte, len(records))
+			for i := range records {
+				data[i] = encode(i)
+			}
+			exp := NewTreeFromData(algo, data, WithMode(m), WithIndexedLeaves())
+			act := NewTreeFromEncoder(algo, len(records), encode, WithMode(m), WithIndexedLeaves())
+			if act.Root().String() != exp.Root().String() {
+				t.Errorf("expected merkle root to be %s, got %s", exp.Root(), act.Root())
+			}
+		})
+	}
+}
+
+func TestHashLeaf(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+
+	t.Run("Should Hash Plain Data", func(t *testing.T) {
+		if exp, act := hashStringSlice(algo, "a")[0], HashLeaf(algo, data[0]); !bytes.Equal(act, exp) {
+			t.Errorf("expected leaf to be %x, got %x", exp, act)
+		}
+	})
+
+	for _, m := range []Mode{ModeSorted, ModeRFC6962, ModeBitcoin} {
+		t.Run("Should Hash As Tree Does In "+m.String()+" Mode", func(t *testing.T) {
+			tree := NewTreeFromData(algo, data, WithMode(m))
+			for _, d := range data {
+				if l := HashLeaf(algo, d, WithMode(m)); !tree.Contains(l) {
+					t.Errorf("expected leaf %x of %s to be part of the tree", l, d)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyData(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+	for name, opts := range map[string][]Option{
+		"Without Prefix": nil,
+		"With Prefix":    {WithLeafPrefix([]byte{0})},
+	} {
+		tree := NewTreeFromData(algo, data, opts...)
+		t.Run(name, func(t *testing.T) {
+			t.Run("Should Be Verified", func(t *testing.T) {
+				for _, d := range data {
+					proof := tree.Proof(tree.c.hashLeaf(algo, d)).ToByteArrays()
+					if !VerifyData(algo, d, tree.Root().Bytes(), proof, opts...) {
+						t.Errorf("proof for %s should have been valid", d)
+					}
+				}
+			})
+			t.Run("Should Not Be Verified With Wrong Data", func(t *testing.T) {
+				proof := tree.Proof(tree.c.hashLeaf(algo, data[0])).ToByteArrays()
+				if VerifyData(algo, []byte("f"), tree.Root().Bytes(), proof, opts...) {
+					t.Errorf("proof should have been invalid")
+				}
+			})
+		})
+	}
+}
+
+func TestVerifyValue(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+	prefix := WithLeafPrefix([]byte{0})
+	tree := NewTreeFromData(algo, data, prefix)
+	proof := tree.Proof(tree.c.hashLeaf(algo, data[2])).ToByteArrays()
+
+	t.Run("Should Be Verified", func(t *testing.T) {
+		if !VerifyValue(algo, data[2], tree.Root().Bytes(), proof, prefix) {
+			t.Errorf("proof should have been valid")
+		}
+	})
+
+	t.Run("Should Not Be Verified With Wrong Value Or Prefix", func(t *testing.T) {
+		if VerifyValue(algo, data[3], tree.Root().Bytes(), proof, prefix) {
+			t.Errorf("proof should have been invalid for wrong value")
+		}
+		if VerifyValue(algo, data[2], tree.Root().Bytes(), proof) {
+			t.Errorf("proof should have been invalid without prefix")
+		}
+	})
+	t.Run("Should Not Be Verified In Positional Modes", func(t *testing.T) {
+		tree := NewTreeFromData(algo, data, WithMode(ModeRFC6962))
+		root := tree.Root().Bytes()
+		for i, d := range data {
+			leaf := HashLeaf(algo, d, WithMode(ModeRFC6962))
+			proof := tree.Proof(leaf).ToByteArrays()
+			if VerifyValue(algo, d, root, proof, WithMode(ModeRFC6962)) {
+				t.Errorf("proof for %s should have been rejected", d)
+			}
+			// as documented, positional proofs are verified by index.
+			if !VerifyMode(algo, ModeRFC6962, leaf, root, proof, i, len(data)) {
+				t.Errorf("proof for %s should have been valid", d)
+			}
+		}
+	})
+}
+
+func TestVerifyIndexed(t *testing.T) {
+	// the same data at two positions yields two distinct leaves.
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("a"), []byte("c"), []byte("d")}
+	tree := NewTreeFromData(algo, data, WithMode(ModeOrdered), WithIndexedLeaves())
+	root := tree.Root().Bytes()
+
+	t.Run("Should Be Verified At Its Index", func(t *testing.T) {
+		for i, d := range data {
+			proof := tree.Proof(tree.leaves[i].val).ToByteArrays()
+			if !VerifyIndexed(algo, i, len(data), d, root, proof) {
+				t.Errorf("proof for %s at %d should have been valid", d, i)
+			}
+		}
+	})
+
+	t.Run("Should Not Be Verified At A Different Index", func(t *testing.T) {
+		proof := tree.Proof(tree.leaves[0].val).ToByteArrays()
+		if VerifyIndexed(algo, 2, len(data), data[0], root, proof) {
+			t.Errorf("proof should have been invalid at a different index")
+		}
+		if tree.leaves[0].String() == tree.leaves[2].String() {
+			t.Errorf("expected same data at different indexes to hash differently")
+		}
+	})
+}
+
+func TestTree_ProveData(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+	tree := NewTreeFromData(algo, data, WithLeafPrefix([]byte{0}))
+
+	t.Run("Should Return Leaf Hash And Proof", func(t *testing.T) {
+		for _, d := range data {
+			leaf, proof, ok := tree.ProveData(d)
+			if !ok {
+				t.Fatalf("expected %s to be found", d)
+			}
+			if !Verify(algo, leaf, tree.Root().Bytes(), proof.ToByteArrays()) {
+				t.Errorf("proof for %s should have been valid", d)
+			}
+		}
+	})
+
+	t.Run("Should Return False For Non Existent Data", func(t *testing.T) {
+		if _, _, ok := tree.ProveData([]byte("f")); ok {
+			t.Errorf("expected data not to be found")
+		}
+	})
+}
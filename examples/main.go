@@ -52,7 +52,5 @@ func main() {
 }
 
 func hashString(algo hash.Hash, s string) []byte {
-	algo.Reset()
-	algo.Write([]byte(s))
-	return algo.Sum(nil)
+	return merkle.HashLeaf(algo, []byte(s))
 }
@@ -0,0 +1,37 @@
+package merkle
+
+import (
+	"hash"
+)
+
+// ForestTree is a merkle tree aggregating independent trees, e.g. one per
+// partition, whose leaves are the merkle roots of such trees. Leaves of the
+// child trees are proven up to the forest root by chaining their proof
+// within the child tree with the proof of the child root within the
+// forest, see ProveForest and VerifyChain.
+type ForestTree struct {
+	*Tree
+}
+
+// NewForestTree makes a new ForestTree with the provided hashing algorithm
+// over the roots of the provided child trees, which are left untouched.
+// The forest is built with the provided Option(s), VerifyChain assumes
+// the default ones for both the child trees and the forest.
+func NewForestTree(h hash.Hash, trees []*Tree, opts ...Option) *ForestTree {
+	roots := make([][]byte, len(trees))
+	for i, t := range trees {
+		roots[i] = t.Root().Bytes()
+	}
+	return &ForestTree{Tree: NewTree(h, roots, opts...)}
+}
+
+// ProveForest builds and returns the merkle proof of the provided child
+// tree root within the forest. It returns ErrLeafNotFound if the root
+// is not the one of any of the child trees.
+func (t ForestTree) ProveForest(childRoot []byte) (Nodes, error) {
+	proof, _, ok := t.ProofWithIndex(childRoot)
+	if !ok {
+		return nil, ErrLeafNotFound
+	}
+	return proof, nil
+}
@@ -0,0 +1,44 @@
+package merkle
+
+import (
+	"testing"
+)
+
+func TestForestTree_ProveForest(t *testing.T) {
+	partitions := []*Tree{
+		NewTree(algo, hashStringSlice(algo, "a", "b", "c")),
+		NewTree(algo, hashStringSlice(algo, "d", "e")),
+		NewTree(algo, hashStringSlice(algo, "f", "g", "h", "i")),
+	}
+	forest := NewForestTree(algo, partitions)
+
+	t.Run("Should Prove Child Roots", func(t *testing.T) {
+		for _, p := range partitions {
+			proof, err := forest.ProveForest(p.Root().Bytes())
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !Verify(algo, p.Root().Bytes(), forest.Root().Bytes(), proof.ToByteArrays()) {
+				t.Errorf("forest proof for child root %s should have been valid", p.Root())
+			}
+		}
+	})
+
+	t.Run("Should Prove Child Leaves End To End", func(t *testing.T) {
+		for _, p := range partitions {
+			forestProof, _ := forest.ProveForest(p.Root().Bytes())
+			for _, l := range p.leaves {
+				proofs := [][][]byte{p.Proof(l.val).ToByteArrays(), forestProof.ToByteArrays()}
+				if !VerifyChain(algo, l.val, proofs, forest.Root().Bytes()) {
+					t.Errorf("chained proof for leaf %s should have been valid", l)
+				}
+			}
+		}
+	})
+
+	t.Run("With Unknown Child Root Should Return ErrLeafNotFound", func(t *testing.T) {
+		if _, err := forest.ProveForest(oddLeavesTree.Root().Bytes()); err != ErrLeafNotFound {
+			t.Errorf("expected ErrLeafNotFound, got %v", err)
+		}
+	})
+}
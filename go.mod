@@ -3,3 +3,8 @@ module github.com/alessandro-c/merkle
 go 1.18
 
 require github.com/xlab/treeprint v1.1.0 // indirect
+
+require (
+	golang.org/x/crypto v0.21.0
+	golang.org/x/sys v0.18.0 // indirect
+)
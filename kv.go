@@ -0,0 +1,109 @@
+package merkle
+
+import (
+	"errors"
+	"hash"
+)
+
+// ErrKeyNotFound is returned when the provided key is not part of the KVTree.
+var ErrKeyNotFound = errors.New("merkle: key not found")
+
+// ErrKeyExists is returned when inserting a key already part of the KVTree.
+var ErrKeyExists = errors.New("merkle: key already exists")
+
+// KVTree is a merkle tree over key/value pairs, authenticating the value
+// of each key. Each pair makes a leaf hashing the key length as an
+// unsigned varint, the key and the value, in this order, the length
+// telling apart where the key ends and the value starts.
+//
+// Insert and Update take key/value pairs rather than leaves, keeping
+// the values in sync with the leaves of the tree.
+type KVTree struct {
+	*Tree
+	values map[string][]byte
+}
+
+// NewKVTree makes a new KVTree with the provided hashing algorithm and
+// key/value pairs, which are copied over. The tree is built with the
+// provided Option(s), VerifyKV assumes the default ones.
+// It panics with ErrIncompatibleOptions if built WithMultiplicities,
+// as keys are unique already and their leaves would be annotated.
+func NewKVTree(h hash.Hash, kv map[string][]byte, opts ...Option) *KVTree {
+	if newConfig(opts...).multiplicities {
+		panic(ErrIncompatibleOptions)
+	}
+	values := make(map[string][]byte, len(kv))
+	hl := make([][]byte, 0, len(kv))
+	for k, v := range kv {
+		values[k] = v
+		hl = append(hl, kvLeaf(h, k, v))
+	}
+	return &KVTree{Tree: NewTree(h, hl, opts...), values: values}
+}
+
+// ProveKV returns the value of the provided key alongside its merkle proof.
+// It returns ErrKeyNotFound if the key is not part of the tree.
+func (t KVTree) ProveKV(key string) ([]byte, Nodes, error) {
+	v, ok := t.values[key]
+	if !ok {
+		return nil, nil, ErrKeyNotFound
+	}
+	t.expand()
+	defer t.c.lock()()
+	proof, _, _ := t.ProofWithIndex(kvLeaf(t.h, key, v))
+	return v, proof, nil
+}
+
+// Insert inserts the provided key/value pair same as Tree.Insert does.
+// It returns ErrKeyExists if the key is part of the tree already, see
+// Update, and ErrNoLeaves for trees built WithoutLeaves.
+// It's not safe for concurrent use.
+func (t *KVTree) Insert(key string, value []byte) error {
+	if t.c.withoutLeaves {
+		return ErrNoLeaves
+	}
+	if _, ok := t.values[key]; ok {
+		return ErrKeyExists
+	}
+	unlock := t.c.lock()
+	leaf := kvLeaf(t.h, key, value)
+	unlock()
+	if err := t.Tree.Insert(leaf); err != nil {
+		return err
+	}
+	t.values[key] = value
+	return nil
+}
+
+// Update replaces the value of the provided key same as Tree.Update does.
+// It returns ErrKeyNotFound if the key is not part of the tree and
+// ErrNoLeaves for trees built WithoutLeaves.
+// It's not safe for concurrent use.
+func (t *KVTree) Update(key string, value []byte) error {
+	old, ok := t.values[key]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	unlock := t.c.lock()
+	oldLeaf, newLeaf := kvLeaf(t.h, key, old), kvLeaf(t.h, key, value)
+	unlock()
+	if err := t.Tree.Update(oldLeaf, newLeaf); err != nil {
+		return err
+	}
+	t.values[key] = value
+	return nil
+}
+
+// VerifyKV verifies whether the provided proof proves that value
+// is the value of key within the KVTree with the provided root.
+func VerifyKV(algo hash.Hash, key string, value, root []byte, proof [][]byte) bool {
+	return Verify(algo, kvLeaf(algo, key, value), root, proof)
+}
+
+// kvLeaf hashes the provided key/value pair into its leaf.
+func kvLeaf(h hash.Hash, key string, value []byte) []byte {
+	h.Reset()
+	writeLengthPrefixed(h, []byte(key))
+	h.Write(value)
+	return h.Sum(nil)
+}
@@ -0,0 +1,81 @@
+package merkle
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestKVTree(t *testing.T) {
+	kv := map[string][]byte{"alice": []byte("10"), "bob": []byte("20"), "carol": []byte("30")}
+	tree := NewKVTree(algo, kv)
+	root := tree.Root().Bytes()
+
+	t.Run("Should Prove Values", func(t *testing.T) {
+		for k, exp := range kv {
+			v, proof, err := tree.ProveKV(k)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !bytes.Equal(v, exp) {
+				t.Errorf("expected value of %s to be %s, got %s", k, exp, v)
+			}
+			if !VerifyKV(algo, k, v, root, proof.ToByteArrays()) {
+				t.Errorf("proof for %s should have been valid", k)
+			}
+		}
+	})
+
+	t.Run("Should Not Verify Wrong Value Or Key", func(t *testing.T) {
+		_, proof, _ := tree.ProveKV("alice")
+		if VerifyKV(algo, "alice", []byte("20"), root, proof.ToByteArrays()) {
+			t.Errorf("proof should have been invalid for wrong value")
+		}
+		// same concatenation of key and value.
+		if VerifyKV(algo, "alice1", []byte("0"), root, proof.ToByteArrays()) {
+			t.Errorf("proof should have been invalid for wrong key")
+		}
+	})
+
+	t.Run("Should Return ErrKeyNotFound", func(t *testing.T) {
+		if _, _, err := tree.ProveKV("dave"); err != ErrKeyNotFound {
+			t.Errorf("expected ErrKeyNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Should Keep Values In Sync On Insert And Update", func(t *testing.T) {
+		tree := NewKVTree(algo, kv)
+		if err := tree.Insert("dave", []byte("40")); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if err := tree.Update("alice", []byte("15")); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		exp := NewKVTree(algo, map[string][]byte{
+			"alice": []byte("15"), "bob": []byte("20"), "carol": []byte("30"), "dave": []byte("40"),
+		})
+		if tree.Root().String() != exp.Root().String() {
+			t.Errorf("expected merkle root to be %s, got %s", exp.Root(), tree.Root())
+		}
+		for _, k := range []string{"alice", "dave"} {
+			v, proof, err := tree.ProveKV(k)
+			if err != nil || !VerifyKV(algo, k, v, tree.Root().Bytes(), proof.ToByteArrays()) {
+				t.Errorf("proof for %s should have been valid, got %v", k, err)
+			}
+		}
+		if err := tree.Insert("dave", []byte("50")); err != ErrKeyExists {
+			t.Errorf("expected ErrKeyExists, got %v", err)
+		}
+		if err := tree.Update("erin", []byte("50")); err != ErrKeyNotFound {
+			t.Errorf("expected ErrKeyNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Should Panic With Multiplicities", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r != ErrIncompatibleOptions {
+				t.Errorf("expected panic with ErrIncompatibleOptions, got %v", r)
+			}
+		}()
+		NewKVTree(algo, kv, WithMultiplicities())
+	})
+}
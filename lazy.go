@@ -0,0 +1,264 @@
+package merkle
+
+import (
+	"bytes"
+	"hash"
+	"sort"
+)
+
+// LazyTree is a merkle tree that only holds its sorted leaves and computes
+// inner nodes on demand, that is, whenever a proof is requested.
+//
+// It trades a higher cost per proof for a much lower upfront memory
+// footprint, which pays off when having a huge set of leaves but
+// requesting proofs just for a handful of them.
+// Roots and proofs are the same as the ones of a Tree built with
+// the same hashing algorithm, leaves and Option(s), the ones
+// RootFromLeaves doesn't support being rejected, see NewLazyTree.
+//
+// Computed nodes are cached, thus a LazyTree is not safe for concurrent use.
+type LazyTree struct {
+	h      hash.Hash
+	c      *config
+	leaves [][]byte
+	root   []byte
+	// cache holds the computed nodes hashes keyed by level and index,
+	// nothing is cached if nil.
+	cache map[lazyKey][]byte
+}
+
+// lazyKey identifies a LazyTree node by its level, starting
+// from 0 at the very bottom, and its index within the level.
+type lazyKey struct {
+	level, index int
+}
+
+// NewLazyTree makes a new LazyTree with the provided hashing algorithm and
+// set of leaves that have been hashed with the same algorithm.
+// No inner node is computed until either Root or Proof is called.
+//
+// Leaves are neither padded nor collapsed, hence it panics with
+// ErrIncompatibleOptions for Option(s) only a Tree applies, that is,
+// WithEmptyHashPadding, WithBlindingPadding, WithSizeCommitment,
+// WithMultiplicities and WithOddHandler.
+func NewLazyTree(h hash.Hash, hl [][]byte, opts ...Option) *LazyTree {
+	c := newConfig(opts...)
+	if !c.foldable() {
+		panic(ErrIncompatibleOptions)
+	}
+	leaves := make([][]byte, len(hl))
+	copy(leaves, hl)
+	if c.mode.sortsLeaves() {
+		sort.Slice(leaves, func(i, j int) bool {
+			return c.less(leaves[i], leaves[j])
+		})
+	}
+	return &LazyTree{
+		h:      h,
+		c:      c,
+		leaves: leaves,
+		cache:  map[lazyKey][]byte{},
+	}
+}
+
+// Root returns the root *Node a.k.a merkle root.
+// The first call forces the whole tree to be computed.
+func (t *LazyTree) Root() *Node {
+	if t.root == nil {
+		t.root = foldLeaves(t.h, t.leaves, t.c)
+	}
+	return newNode(t.root)
+}
+
+// Proof builds and returns the merkle proof for the provided hashed leaf,
+// computing just the siblings along the leaf's path up to the root.
+// The returned Nodes are detached, hence they have no parent nor children.
+func (t *LazyTree) Proof(hl []byte) Nodes {
+	i, ok := t.leafIndex(hl)
+	if !ok {
+		return Nodes{}
+	}
+
+	proof := Nodes{}
+	for level, n := 0, len(t.leaves); n > 1; level, n = level+1, (n+1)/2 {
+		// an odd node at the end of the level has no sibling as it
+		// gets either promoted to the level above or paired with itself.
+		if sibling := i ^ 1; sibling < n {
+			proof = append(proof, newNode(t.node(level, sibling)))
+		} else if t.c.duplicateOdd {
+			proof = append(proof, newNode(t.node(level, i)))
+		}
+		i /= 2
+	}
+
+	return proof
+}
+
+// ProofFromLeaves builds the merkle proof for the provided hashed leaf out
+// of the already sorted leaves, computing just the siblings along the leaf
+// path without ever materialising the tree. Being each sibling folded from
+// the leaves it commits to, at most half of them are held at once.
+// The proof is the same Tree built with the same leaves would build.
+// It returns ErrLeafNotFound if the leaf is not part of the leaves and
+// ErrIncompatibleOptions for the Option(s) NewLazyTree rejects.
+func ProofFromLeaves(h hash.Hash, sortedLeaves [][]byte, leaf []byte, opts ...Option) ([][]byte, error) {
+	c := newConfig(opts...)
+	if !c.foldable() {
+		return nil, ErrIncompatibleOptions
+	}
+	// leaves are trusted to be sorted, hence not copied over,
+	// and siblings are computed once each, hence not cached.
+	t := &LazyTree{h: h, c: c, leaves: sortedLeaves}
+	if _, ok := t.leafIndex(leaf); !ok {
+		return nil, ErrLeafNotFound
+	}
+	return t.Proof(leaf).ToByteArrays(), nil
+}
+
+// LeanTree is a merkle tree holding just its sorted leaves and its root,
+// recomputing the inner nodes of each proof from the leaves on demand.
+//
+// It's a middle ground between a Tree and RootFromLeaves: having no inner
+// Node at all, it takes roughly half the memory of a Tree, at the cost of
+// O(n) hashing per proof rather than O(log n) lookups. Unlike LazyTree
+// nothing is cached, thus memory doesn't grow as proofs are requested.
+//
+// It's not safe for concurrent use as it shares the hashing algorithm.
+type LeanTree struct {
+	lazy *LazyTree
+}
+
+// NewLeanTree makes a new LeanTree with the provided hashing algorithm and
+// set of leaves that have been hashed with the same algorithm, computing
+// its root straight away. Roots and proofs are the same as the ones of a
+// Tree built with the same hashing algorithm, leaves and Option(s), as
+// long as they're supported, it panics with ErrIncompatibleOptions for
+// the ones NewLazyTree rejects, e.g. WithEmptyHashPadding.
+func NewLeanTree(h hash.Hash, hl [][]byte, opts ...Option) *LeanTree {
+	lazy := NewLazyTree(h, hl, opts...)
+	lazy.cache = nil
+	lazy.Root()
+	return &LeanTree{lazy: lazy}
+}
+
+// Root returns the root *Node a.k.a merkle root.
+func (t LeanTree) Root() *Node {
+	return t.lazy.Root()
+}
+
+// Proof builds and returns the merkle proof for the provided hashed leaf,
+// recomputing the siblings along the leaf's path from the leaves.
+// The returned Nodes are detached, hence they have no parent nor children.
+func (t LeanTree) Proof(hl []byte) Nodes {
+	return t.lazy.Proof(hl)
+}
+
+// Contains tells whether the provided hashed leaf is part of the tree.
+func (t LeanTree) Contains(hl []byte) bool {
+	_, ok := t.lazy.leafIndex(hl)
+	return ok
+}
+
+// node returns the hash of the node at the provided level and index.
+func (t *LazyTree) node(level, index int) []byte {
+	k := lazyKey{level, index}
+	if h, ok := t.cache[k]; ok {
+		return h
+	}
+	// the node commits to a contiguous range of leaves which,
+	// being aligned to a power of two, can be folded on its own.
+	lo := index << level
+	hi := lo + 1<<level
+	if hi > len(t.leaves) {
+		hi = len(t.leaves)
+	}
+	h := foldLevels(t.h, t.leaves[lo:hi], t.c, level)
+	if t.cache != nil {
+		t.cache[k] = h
+	}
+	return h
+}
+
+// leafIndex finds the index of the provided hashed leaf
+// within the leaves, reporting whether it was found.
+func (t *LazyTree) leafIndex(hl []byte) (int, bool) {
+	if !t.c.mode.sortsLeaves() {
+		for i, l := range t.leaves {
+			if bytes.Equal(l, hl) {
+				return i, true
+			}
+		}
+		return len(t.leaves), false
+	}
+	return t.c.search(len(t.leaves), func(i int) []byte {
+		return t.leaves[i]
+	}, hl)
+}
+
+// foldLeaves computes the merkle root of the provided sorted leaves the same
+// way buildTree does, without allocating any Node along the way.
+// It returns nil if no leaves are provided.
+func foldLeaves(h hash.Hash, hl [][]byte, c *config) []byte {
+	return foldLevels(h, hl, c, height(len(hl)))
+}
+
+// foldLevels folds the provided leaves exactly levels times, which may be
+// more than needed to reach a single node for subtrees at the end of a tree
+// whose lone nodes are paired with themselves when duplicating odd nodes.
+// It returns nil if no leaves are provided.
+func foldLevels(h hash.Hash, hl [][]byte, c *config, levels int) []byte {
+	if len(hl) == 0 {
+		return nil
+	}
+
+	level := make([][]byte, len(hl))
+	copy(level, hl)
+	var sizes []int
+	if c.sized {
+		sizes = make([]int, len(hl))
+		for i := range sizes {
+			sizes[i] = 1
+		}
+	}
+
+	for i := 0; i < levels; i++ {
+		lh := c.hasher(h, i)
+		// parents are written in place as they never
+		// overtake the pair being currently hashed.
+		for k := 0; k+1 < len(level); k += 2 {
+			l, r := level[k], level[k+1]
+			if !c.mode.positional() {
+				l, r = c.order(l, r)
+			}
+			size := 0
+			if c.sized {
+				size = sizes[k] + sizes[k+1]
+				sizes[k/2] = size
+			}
+			level[k/2] = c.cachedCombine(lh, l, r, size)
+		}
+		// promoting or duplicating the eventual odd node.
+		if last := len(level) - 1; last%2 == 0 {
+			level[last/2] = level[last]
+			if c.duplicateOdd {
+				level[last/2] = c.cachedCombine(lh, level[last], level[last], 0)
+			}
+			if c.sized {
+				sizes[last/2] = sizes[last]
+			}
+		}
+		level = level[:(len(level)+1)/2]
+	}
+
+	return level[0]
+}
+
+// height returns the number of levels above the
+// leaves of a tree with n leaves, 0 for a single leaf.
+func height(n int) int {
+	levels := 0
+	for ; n > 1; n = (n + 1) / 2 {
+		levels++
+	}
+	return levels
+}
@@ -0,0 +1,158 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash"
+	"strconv"
+	"testing"
+)
+
+func TestLazyTree(t *testing.T) {
+	sized := WithSizedCombine(func(h hash.Hash, l, r []byte, size int) []byte {
+		h.Reset()
+		binary.Write(h, binary.BigEndian, uint64(size)) // nolint:errcheck
+		h.Write(l)
+		h.Write(r)
+		return h.Sum(nil)
+	})
+
+	for _, n := range []int{1, 2, 3, 4, 5, 7, 8, 9, 13} {
+		leaves := make([]string, n)
+		for i := range leaves {
+			leaves[i] = string(rune('a' + i))
+		}
+		hl := hashStringSlice(algo, leaves...)
+
+		for name, opts := range map[string][]Option{"Default": nil, "Sized Combine": {sized}} {
+			tree := NewTree(algo, hl, opts...)
+			lazy := NewLazyTree(algo, hl, opts...)
+
+			t.Run(name+" Should Return Same Root With "+strconv.Itoa(n)+" Leaves", func(t *testing.T) {
+				if !bytes.Equal(tree.Root().Bytes(), lazy.Root().Bytes()) {
+					t.Errorf("expected merkle root to be %s, got %s", tree.Root(), lazy.Root())
+				}
+			})
+
+			t.Run(name+" Should Return Same Proofs With "+strconv.Itoa(n)+" Leaves", func(t *testing.T) {
+				for _, l := range hl {
+					exp := tree.Proof(l).ToHexStrings()
+					act := lazy.Proof(l).ToHexStrings()
+					if len(exp) != len(act) {
+						t.Fatalf("expected length of proof to be %d, got %d", len(exp), len(act))
+					}
+					for i := range exp {
+						if exp[i] != act[i] {
+							t.Errorf("expected node at index %d to be %s, got %s", i, exp[i], act[i])
+						}
+					}
+				}
+			})
+		}
+	}
+
+	t.Run("With Non Existent Leaf Should Return Empty Proof", func(t *testing.T) {
+		if proof := NewLazyTree(algo, hashStringSlice(algo, "a", "b")).Proof([]byte("foo")); len(proof) > 0 {
+			t.Errorf("expected empty proof")
+		}
+	})
+
+	for name, opt := range map[string]Option{
+		"Empty Padding":    WithEmptyHashPadding(),
+		"Blinding Padding": WithBlindingPadding(8, nil),
+		"Size Commitment":  WithSizeCommitment(),
+		"Multiplicities":   WithMultiplicities(),
+		"Odd Handler": WithOddHandler(func(odd *Node, _ int) *Node {
+			return odd
+		}),
+	} {
+		t.Run("Should Panic With "+name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != ErrIncompatibleOptions {
+					t.Errorf("expected panic with ErrIncompatibleOptions, got %v", r)
+				}
+			}()
+			NewLazyTree(algo, hashStringSlice(algo, "a", "b", "c"), opt)
+		})
+	}
+}
+
+func TestProofFromLeaves(t *testing.T) {
+	for _, tree := range []*Tree{oddLeavesTree, evenLeavesTree} {
+		leaves := tree.leaves.ToByteArrays()
+		for _, l := range leaves {
+			t.Run("Should Return Same Proof As Tree For "+newNode(l).Hex(), func(t *testing.T) {
+				proof, err := ProofFromLeaves(algo, leaves, l)
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				exp := tree.Proof(l).ToByteArrays()
+				if len(proof) != len(exp) {
+					t.Fatalf("expected proof of length %d, got %d", len(exp), len(proof))
+				}
+				for i := range exp {
+					if !bytes.Equal(proof[i], exp[i]) {
+						t.Errorf("expected proof node %d to be %x, got %x", i, exp[i], proof[i])
+					}
+				}
+			})
+		}
+	}
+
+	t.Run("Should Return ErrLeafNotFound", func(t *testing.T) {
+		if _, err := ProofFromLeaves(algo, oddLeavesTree.leaves.ToByteArrays(), hashStringSlice(algo, "f")[0]); err != ErrLeafNotFound {
+			t.Errorf("expected ErrLeafNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Should Return ErrIncompatibleOptions", func(t *testing.T) {
+		leaves := oddLeavesTree.leaves.ToByteArrays()
+		if _, err := ProofFromLeaves(algo, leaves, leaves[0], WithEmptyHashPadding()); err != ErrIncompatibleOptions {
+			t.Errorf("expected ErrIncompatibleOptions, got %v", err)
+		}
+	})
+}
+
+func TestLeanTree(t *testing.T) {
+	for _, exp := range []*Tree{oddLeavesTree, evenLeavesTree} {
+		tree := NewLeanTree(algo, exp.leaves.ToByteArrays())
+
+		t.Run("Should Return Expected Merkle Root", func(t *testing.T) {
+			if act := tree.Root().String(); act != exp.Root().String() {
+				t.Errorf("expected merkle root should have been %s, got %s", exp.Root(), act)
+			}
+		})
+
+		t.Run("Should Return Same Proofs As Tree", func(t *testing.T) {
+			for _, l := range exp.leaves {
+				if !tree.Contains(l.val) {
+					t.Errorf("expected leaf %s to be contained", l)
+				}
+				expProof, actProof := exp.Proof(l.val).ToHexStrings(), tree.Proof(l.val).ToHexStrings()
+				if len(actProof) != len(expProof) {
+					t.Fatalf("expected proof of length %d, got %d", len(expProof), len(actProof))
+				}
+				for i := range expProof {
+					if actProof[i] != expProof[i] {
+						t.Errorf("expected node at index %d to be %s, got %s", i, expProof[i], actProof[i])
+					}
+				}
+			}
+		})
+
+		t.Run("Should Not Contain Non Existent Leaf", func(t *testing.T) {
+			if tree.Contains(hashStringSlice(algo, "f")[0]) {
+				t.Errorf("expected leaf not to be contained")
+			}
+		})
+	}
+
+	t.Run("Should Panic With Unsupported Options", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r != ErrIncompatibleOptions {
+				t.Errorf("expected panic with ErrIncompatibleOptions, got %v", r)
+			}
+		}()
+		NewLeanTree(algo, hashStringSlice(algo, "a", "b", "c"), WithSizeCommitment())
+	})
+}
@@ -0,0 +1,75 @@
+package merkle
+
+import (
+	"hash"
+)
+
+// Log is an append-only log of entries backed by an ordered tree, whose
+// leaves commit to the sequence number of each entry, that is, each leaf
+// is H(seq || entry), seq being a big endian uint64 starting from 0.
+// A proof thus demonstrates that an entry was at a given position.
+// It's not safe for concurrent use.
+type Log struct {
+	h    hash.Hash
+	c    *config
+	opts []Option
+	tree *Tree
+}
+
+// NewLog makes a new empty Log with the provided hashing algorithm. The
+// tree is built in ModeOrdered WithIndexedLeaves, the Mode can be overridden
+// with Option(s), e.g. WithMode(ModeRFC6962), in which case VerifyLog must
+// be provided the same Option(s).
+func NewLog(h hash.Hash, opts ...Option) *Log {
+	opts = append(append([]Option{WithMode(ModeOrdered)}, opts...), WithIndexedLeaves())
+	return &Log{h: h, c: newConfig(opts...), opts: opts}
+}
+
+// Append appends the provided entry to the Log, returning its sequence
+// number. The tree is rebuilt, which takes O(n) hashing. It panics with
+// ErrMaxDepth if the log would exceed the depth set WithMaxDepth.
+func (l *Log) Append(entry []byte) (seq int) {
+	seq = l.Size()
+	unlock := l.c.lock()
+	leaf := l.c.hashLeafAt(l.h, seq, entry)
+	unlock()
+	if l.tree == nil {
+		l.tree = NewTree(l.h, [][]byte{leaf}, l.opts...)
+	} else if err := l.tree.Insert(leaf); err != nil {
+		panic(err)
+	}
+	return seq
+}
+
+// Size returns the number of entries of the Log.
+func (l *Log) Size() int {
+	if l.tree == nil {
+		return 0
+	}
+	return len(l.tree.leaves)
+}
+
+// Root returns the root *Node a.k.a merkle root, nil if the Log is empty.
+func (l *Log) Root() *Node {
+	if l.tree == nil {
+		return nil
+	}
+	return l.tree.Root()
+}
+
+// Prove builds and returns the merkle proof of the entry at the provided
+// sequence number, which is bound to it as the sequence number is part of
+// the leaf. It returns ErrLeafNotFound if there's no such entry.
+func (l *Log) Prove(seq int) (Nodes, error) {
+	if seq < 0 || seq >= l.Size() {
+		return nil, ErrLeafNotFound
+	}
+	return l.tree.proofAt(seq, Nodes{}), nil
+}
+
+// VerifyLog verifies whether the provided proof proves that entry was at
+// the provided sequence number within the Log of size entries with root.
+// The Mode can be overridden with Option(s), same as NewLog.
+func VerifyLog(algo hash.Hash, root []byte, seq, size int, entry []byte, proof [][]byte, opts ...Option) bool {
+	return VerifyIndexed(algo, seq, size, entry, root, proof, opts...)
+}
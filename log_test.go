@@ -0,0 +1,58 @@
+package merkle
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+func TestLog(t *testing.T) {
+	entries := make([][]byte, 9)
+	for i := range entries {
+		entries[i] = []byte("entry " + strconv.Itoa(i))
+	}
+
+	t.Run("Should Be Empty", func(t *testing.T) {
+		log := NewLog(algo)
+		if log.Size() != 0 || log.Root() != nil {
+			t.Errorf("expected an empty log")
+		}
+		if _, err := log.Prove(0); err != ErrLeafNotFound {
+			t.Errorf("expected ErrLeafNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Should Commit To Sequence Numbers", func(t *testing.T) {
+		log := NewLog(algo)
+		log.Append(entries[0])
+		var seq [8]byte
+		exp := hashStringSlice(algo, string(append(seq[:], entries[0]...)))[0]
+		if !bytes.Equal(log.Root().Bytes(), exp) {
+			t.Errorf("expected merkle root to be %x, got %s", exp, log.Root())
+		}
+	})
+
+	for _, opts := range [][]Option{nil, {WithMode(ModeRFC6962)}} {
+		log := NewLog(algo, opts...)
+		t.Run("Should Prove Entries At Their Position", func(t *testing.T) {
+			for i, e := range entries {
+				if seq := log.Append(e); seq != i {
+					t.Fatalf("expected sequence number %d, got %d", i, seq)
+				}
+				for j := 0; j <= i; j++ {
+					proof, err := log.Prove(j)
+					if err != nil {
+						t.Fatalf("unexpected error %v", err)
+					}
+					root, p := log.Root().Bytes(), proof.ToByteArrays()
+					if !VerifyLog(algo, root, j, log.Size(), entries[j], p, opts...) {
+						t.Errorf("proof of entry %d of %d should have been valid", j, log.Size())
+					}
+					if j > 0 && VerifyLog(algo, root, j-1, log.Size(), entries[j], p, opts...) {
+						t.Errorf("proof of entry %d of %d should have been invalid at %d", j, log.Size(), j-1)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package merkle
+
+import (
+	"fmt"
+	"hash"
+)
+
+// Mode is the scheme a tree is constructed with.
+// Proofs can only be verified with the very same Mode
+// the tree they've been built from was constructed with.
+type Mode int
+
+const (
+	// ModeSorted is the default scheme of this package, leaves as well as
+	// children pairs are sorted and odd nodes are promoted to the level above.
+	ModeSorted Mode = iota
+	// ModeBitcoin keeps leaves in the provided order, odd nodes are
+	// paired with themselves and children pairs are double hashed,
+	// that is, H(H(l || r)), same as Bitcoin's merkle trees.
+	ModeBitcoin
+	// ModeRFC6962 keeps leaves in the provided order, odd nodes are
+	// promoted to the level above and children pairs are hashed with
+	// a 0x01 prefix while raw leaves data is hashed with a 0x00 prefix,
+	// same as Certificate Transparency logs merkle trees.
+	ModeRFC6962
+	// ModeOrdered keeps leaves in the provided order and odd nodes are
+	// promoted to the level above, children pairs are hashed by position.
+	ModeOrdered
+	// ModeUnsorted sorts leaves same as ModeSorted but children pairs are
+	// hashed by position rather than being sorted, hence verifying proofs
+	// requires the orientation of each sibling, see ProofSteps.
+	ModeUnsorted
+)
+
+// modeNames maps each Mode to its name.
+var modeNames = map[Mode]string{
+	ModeSorted:   "sorted",
+	ModeBitcoin:  "bitcoin",
+	ModeRFC6962:  "rfc6962",
+	ModeOrdered:  "ordered",
+	ModeUnsorted: "unsorted",
+}
+
+// String implements the fmt.Stringer interface.
+func (m Mode) String() string {
+	if name, ok := modeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (m Mode) MarshalText() ([]byte, error) {
+	if _, ok := modeNames[m]; !ok {
+		return nil, fmt.Errorf("merkle: unknown mode %d", int(m))
+	}
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (m *Mode) UnmarshalText(text []byte) error {
+	for mode, name := range modeNames {
+		if name == string(text) {
+			*m = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("merkle: unknown mode %q", text)
+}
+
+// positional tells whether the Mode hashes
+// children pairs by position rather than sorting them.
+func (m Mode) positional() bool {
+	return m != ModeSorted
+}
+
+// sortsLeaves tells whether the Mode sorts leaves
+// rather than keeping them in the provided order.
+func (m Mode) sortsLeaves() bool {
+	return m == ModeSorted || m == ModeUnsorted
+}
+
+// WithMode sets the scheme the tree is constructed with, ModeSorted
+// by default. Option(s) provided after WithMode take precedence over
+// the ones set by the Mode, e.g. WithCombine overrides its hashing.
+// Conversely, WithMode resets the way leaves and pairs are hashed to the
+// ones of the Mode, that is, it drops WithCombine, WithSizedCombine,
+// WithLengthPrefix and WithLeafPrefix provided before it.
+func WithMode(m Mode) Option {
+	return func(c *config) {
+		c.mode = m
+		c.duplicateOdd = m == ModeBitcoin
+		c.leafPrefix = nil
+		c.lengthPrefix = false
+		c.sized = false
+		c.combine = func(h hash.Hash, l, r []byte, _ int) []byte {
+			return combine(h, l, r)
+		}
+		switch m {
+		case ModeBitcoin:
+			c.combine = func(h hash.Hash, l, r []byte, _ int) []byte {
+				return rehash(h, combine(h, l, r))
+			}
+		case ModeRFC6962:
+			c.leafPrefix = []byte{0x00}
+			c.combine = func(h hash.Hash, l, r []byte, _ int) []byte {
+				h.Reset()
+				h.Write([]byte{0x01})
+				h.Write(l)
+				h.Write(r)
+				return h.Sum(nil)
+			}
+		}
+	}
+}
+
+// Mode returns the scheme the tree was constructed with.
+func (t Tree) Mode() Mode {
+	return t.c.mode
+}
+
+// VerifyMode verifies whether the provided proof for leaf is valid for
+// a tree constructed with the provided Mode. Positional modes need the
+// leaf index and the tree size, that is, its number of leaves, to tell
+// left siblings from right ones, which are otherwise ignored.
+func VerifyMode(algo hash.Hash, mode Mode, leaf, root []byte, proof [][]byte, index, size int) bool {
+	return newConfig(WithMode(mode)).verifyAt(algo, leaf, root, proof, index, size)
+}
+
+// VerifyByIndex verifies whether the provided proof for leaf is valid for a
+// tree built in ModeOrdered, e.g. a power of two padded one, deriving the
+// concatenation order at each level from the bits of the leaf index rather
+// than comparing hashes, same as fixed layout on-chain verifiers do.
+// The treeSize is needed to tell the levels where the leaf is promoted.
+// The Mode can be overridden with Option(s), e.g. WithMode(ModeRFC6962).
+func VerifyByIndex(algo hash.Hash, leaf, root []byte, proof [][]byte, index, treeSize int, opts ...Option) bool {
+	c := newConfig(append([]Option{WithMode(ModeOrdered)}, opts...)...)
+	return c.verifyAt(algo, leaf, root, proof, index, treeSize)
+}
+
+// VerifyLevelHashed verifies whether the provided proof for leaf is valid
+// for a tree built WithLevelHasher(levelHasher), hashing each step with
+// the algorithm of its level. Being the levels where the leaf is promoted
+// left out of the proof, the leaf index, within the sorted leaves in
+// modes sorting them, and the tree size are needed to tell the level of
+// each step. The tree is assumed to be built in ModeSorted unless
+// overridden with Option(s), e.g. WithMode(ModeRFC6962).
+func VerifyLevelHashed(levelHasher func(level int) hash.Hash, leaf, root []byte, proof [][]byte, index, size int, opts ...Option) bool {
+	c := newConfig(append(opts, WithLevelHasher(levelHasher))...)
+	return c.verifyAt(levelHasher(0), leaf, root, proof, index, size)
+}
+
+// rehash hashes the provided hash once more.
+func rehash(h hash.Hash, b []byte) []byte {
+	h.Reset()
+	h.Write(b)
+	return h.Sum(nil)
+}
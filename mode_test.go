@@ -0,0 +1,209 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"testing"
+)
+
+// reverseBytes returns a reversed copy of b, e.g. to turn
+// Bitcoin's hashes from their display to their internal byte order.
+func reverseBytes(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
+
+// verifyAllModeProofs verifies the proofs of all of the tree leaves.
+func verifyAllModeProofs(t *testing.T, tree *Tree) {
+	for i, l := range tree.leaves {
+		proof := tree.Proof(l.val).ToByteArrays()
+		if !VerifyMode(algo, tree.Mode(), l.val, tree.Root().Bytes(), proof, i, len(tree.leaves)) {
+			t.Errorf("proof for leaf %s at index %d should have been valid", l, i)
+		}
+	}
+}
+
+func TestWithMode(t *testing.T) {
+	t.Run("Sorted", func(t *testing.T) {
+		tree := NewTree(algo, hashStringSlice(algo, "a", "b", "c", "d", "e"), WithMode(ModeSorted))
+		if exp, act := oddLeavesTree.Root().String(), tree.Root().String(); act != exp {
+			t.Errorf("expected merkle root should have been %s, got %s", exp, act)
+		}
+		verifyAllModeProofs(t, tree)
+	})
+
+	t.Run("Ordered", func(t *testing.T) {
+		leaves := hashStringSlice(algo, "a", "b", "c", "d", "e")
+		tree := NewTree(algo, leaves, WithMode(ModeOrdered))
+		t.Run("Should Keep Leaves Order", func(t *testing.T) {
+			for i, l := range tree.leaves {
+				if !bytes.Equal(l.val, leaves[i]) {
+					t.Errorf("expected leaf at index %d to be %x, got %s", i, leaves[i], l)
+				}
+			}
+		})
+		t.Run("Should Return Expected Merkle Root", func(t *testing.T) {
+			ab := combine(algo, leaves[0], leaves[1])
+			cd := combine(algo, leaves[2], leaves[3])
+			exp := combine(algo, combine(algo, ab, cd), leaves[4])
+			if !bytes.Equal(tree.Root().Bytes(), exp) {
+				t.Errorf("expected merkle root should have been %x, got %s", exp, tree.Root())
+			}
+		})
+		t.Run("Should Verify Proofs", func(t *testing.T) {
+			verifyAllModeProofs(t, tree)
+		})
+		t.Run("Should Not Verify Proof At Wrong Index", func(t *testing.T) {
+			proof := tree.Proof(leaves[0]).ToByteArrays()
+			if VerifyMode(algo, ModeOrdered, leaves[0], tree.Root().Bytes(), proof, 1, len(leaves)) {
+				t.Errorf("proof should have been invalid")
+			}
+		})
+	})
+
+	t.Run("RFC6962", func(t *testing.T) {
+		// test vectors from Certificate Transparency.
+		data := hexStringsToByteArrays("", "00", "10", "2021", "3031", "40414243",
+			"5051525354555657", "606162636465666768696a6b6c6d6e6f")
+		roots := []string{
+			"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+			"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+			"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+			"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+			"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+			"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+			"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+			"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+		}
+		for size := 1; size <= len(data); size++ {
+			tree := NewTreeFromData(algo, data[:size], WithMode(ModeRFC6962))
+			if exp, act := roots[size-1], tree.Root().Hex(); act != exp {
+				t.Errorf("expected merkle root of size %d to be %s, got %s", size, exp, act)
+			}
+			verifyAllModeProofs(t, tree)
+		}
+	})
+
+	t.Run("Bitcoin", func(t *testing.T) {
+		// transactions of block 100000, in display byte order.
+		txs := hexStringsToByteArrays(
+			"8c14f0db3df150123e6f3dbbf30f8b955a8249b62ac1d1ff16284aefa3d06d87",
+			"fff2525b8931402dd09222c50775608f75787bd2b87e56995a7bdd30f79702c4",
+			"6359f0868171b1d194cbee1af2f16ea598ae8fad666d9b012c8ed2b79a236ec4",
+			"e9a66845e05d5abc0ad04ec80f774a7e585c6e8db975962d069a522137b80c1d",
+		)
+		for i := range txs {
+			txs[i] = reverseBytes(txs[i])
+		}
+		t.Run("Should Return Block Merkle Root", func(t *testing.T) {
+			tree := NewTree(sha256.New(), txs, WithMode(ModeBitcoin))
+			exp := "f3e94742aca4b5ef85488dc37c06c3282295ffec960994b2c0d5ac2a25a95766"
+			if act := hex.EncodeToString(reverseBytes(tree.Root().Bytes())); act != exp {
+				t.Errorf("expected merkle root to be %s, got %s", exp, act)
+			}
+			verifyAllModeProofs(t, tree)
+		})
+		t.Run("Should Duplicate Odd Nodes", func(t *testing.T) {
+			tree := NewTree(sha256.New(), txs[:3], WithMode(ModeBitcoin))
+			dh := func(l, r []byte) []byte { return rehash(algo, combine(algo, l, r)) }
+			exp := dh(dh(txs[0], txs[1]), dh(txs[2], txs[2]))
+			if !bytes.Equal(tree.Root().Bytes(), exp) {
+				t.Errorf("expected merkle root to be %x, got %s", exp, tree.Root())
+			}
+			verifyAllModeProofs(t, tree)
+		})
+		t.Run("Should Return The Leaf As Root Of A Single Leaf", func(t *testing.T) {
+			if tree := NewTree(sha256.New(), txs[:1], WithMode(ModeBitcoin)); !bytes.Equal(tree.Root().Bytes(), txs[0]) {
+				t.Errorf("expected merkle root to be %x, got %s", txs[0], tree.Root())
+			}
+		})
+	})
+
+	t.Run("Should Reset Hashing Options Provided Before", func(t *testing.T) {
+		data := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+		sized := WithSizedCombine(func(h hash.Hash, l, r []byte, size int) []byte {
+			return combine(h, append([]byte{byte(size)}, l...), r)
+		})
+		exp := NewTreeFromData(algo, data, WithMode(ModeOrdered))
+		for name, opt := range map[string]Option{
+			"Combine":       WithCombine(func(h hash.Hash, l, r []byte) []byte { return combine(h, r, l) }),
+			"Sized Combine": sized,
+			"Length Prefix": WithLengthPrefix(),
+			"Leaf Prefix":   WithLeafPrefix([]byte{0x07}),
+		} {
+			c := newConfig(opt, WithMode(ModeOrdered))
+			if c.sized || c.lengthPrefix || c.leafPrefix != nil {
+				t.Errorf("%s: expected hashing options to be reset", name)
+			}
+			if tree := NewTreeFromData(algo, data, opt, WithMode(ModeOrdered)); tree.Root().String() != exp.Root().String() {
+				t.Errorf("%s: expected merkle root to be %s, got %s", name, exp.Root(), tree.Root())
+			}
+		}
+	})
+}
+
+func TestMode_Text(t *testing.T) {
+	for _, m := range []Mode{ModeSorted, ModeBitcoin, ModeRFC6962, ModeOrdered, ModeUnsorted} {
+		text, err := m.MarshalText()
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		var act Mode
+		if err := act.UnmarshalText(text); err != nil || act != m {
+			t.Errorf("expected mode %s to round trip, got %s, %v", m, act, err)
+		}
+	}
+	if _, err := Mode(42).MarshalText(); err == nil {
+		t.Errorf("expected an error marshalling unknown mode")
+	}
+}
+
+func TestModes_LazyTree(t *testing.T) {
+	for _, m := range []Mode{ModeSorted, ModeBitcoin, ModeRFC6962, ModeOrdered, ModeUnsorted} {
+		for n := 1; n <= 9; n++ {
+			hl := hashStringSlice(algo, "a", "b", "c", "d", "e", "f", "g", "h", "i")[:n]
+			tree := NewTree(algo, hl, WithMode(m))
+			lazy := NewLazyTree(algo, hl, WithMode(m))
+			if !bytes.Equal(tree.Root().Bytes(), lazy.Root().Bytes()) {
+				t.Errorf("expected %s merkle root of %d leaves to be %s, got %s", m, n, tree.Root(), lazy.Root())
+			}
+			for _, l := range hl {
+				exp, act := tree.Proof(l).ToHexStrings(), lazy.Proof(l).ToHexStrings()
+				if len(exp) != len(act) {
+					t.Errorf("expected %s proof length of %d leaves to be %d, got %d", m, n, len(exp), len(act))
+					continue
+				}
+				for i := range exp {
+					if exp[i] != act[i] {
+						t.Errorf("expected %s proof node at index %d to be %s, got %s", m, i, exp[i], act[i])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestVerifyByIndex(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e", "f", "g", "h")
+	tree := NewTree(algo, hl, WithMode(ModeOrdered))
+	root := tree.Root().Bytes()
+
+	t.Run("Should Be Verified At Leaf Index", func(t *testing.T) {
+		for i, l := range hl {
+			if !VerifyByIndex(algo, l, root, tree.Proof(l).ToByteArrays(), i, len(hl)) {
+				t.Errorf("proof for leaf %d should have been valid", i)
+			}
+		}
+	})
+
+	t.Run("Should Not Be Verified At Another Index", func(t *testing.T) {
+		if VerifyByIndex(algo, hl[0], root, tree.Proof(hl[0]).ToByteArrays(), 1, len(hl)) {
+			t.Errorf("proof should have been invalid")
+		}
+	})
+}
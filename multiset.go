@@ -0,0 +1,146 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash"
+	"sort"
+)
+
+// WithMultiplicities collapses equal leaves into a single leaf annotated
+// with the number of times it occurs, that is, its multiplicity, so that
+// multisets don't grow the tree with duplicates while still proving how
+// many times each leaf occurs, see ProveMultiplicity.
+//
+// The tree is built out of H(leaf || count) leaves, count being a big
+// endian uint64, hence its leaves and proofs are the ones of the annotated
+// leaves, which can be verified with VerifyMultiplicity.
+// It's meant for modes sorting leaves, where equal leaves are adjacent,
+// and it's applied by NewTree only. Insert and Update keep counting
+// the leaves they're provided with rather than adding duplicates.
+func WithMultiplicities() Option {
+	return func(c *config) {
+		c.multiplicities = true
+	}
+}
+
+// ProveMultiplicity returns the merkle proof for the provided hashed leaf
+// alongside the number of times it occurs within the tree, which must be
+// built WithMultiplicities. The proof is the one of the annotated leaf.
+// It returns ErrLeafNotFound if the leaf is not part of the tree.
+func (t Tree) ProveMultiplicity(hl []byte) (Nodes, int, error) {
+	count, ok := t.counts[string(hl)]
+	if !ok {
+		return nil, 0, ErrLeafNotFound
+	}
+	t.expand()
+	defer t.c.lock()()
+	proof, _, ok := t.ProofWithIndex(multiplicityLeaf(t.h, hl, count))
+	if !ok {
+		return nil, 0, ErrLeafNotFound
+	}
+	return proof, count, nil
+}
+
+// VerifyMultiplicity verifies whether the provided proof proves that the
+// provided hashed leaf occurs count times within the tree with the provided
+// root, which must have been built WithMultiplicities in ModeSorted.
+func VerifyMultiplicity(algo hash.Hash, hl []byte, count int, root []byte, proof [][]byte) bool {
+	return Verify(algo, multiplicityLeaf(algo, hl, count), root, proof)
+}
+
+// collapse sorts the provided hashed leaves and collapses each run of
+// equal ones into their annotated leaf, returning the annotated leaves
+// alongside the multiplicity of each leaf.
+func (c *config) collapse(h hash.Hash, hl [][]byte) ([][]byte, map[string]int) {
+	sorted := make([][]byte, len(hl))
+	copy(sorted, hl)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return c.less(sorted[i], sorted[j])
+	})
+
+	defer c.lock()()
+	counts := make(map[string]int, len(sorted))
+	collapsed := make([][]byte, 0, len(sorted))
+	for i := 0; i < len(sorted); {
+		j := i + 1
+		for j < len(sorted) && bytes.Equal(sorted[j], sorted[i]) {
+			j++
+		}
+		counts[string(sorted[i])] = j - i
+		collapsed = append(collapsed, multiplicityLeaf(h, sorted[i], j-i))
+		i = j
+	}
+	return collapsed, counts
+}
+
+// multiplicityLeaf hashes the provided hashed leaf with its count.
+func multiplicityLeaf(h hash.Hash, hl []byte, count int) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(count))
+	h.Reset()
+	h.Write(hl)
+	h.Write(b[:])
+	return h.Sum(nil)
+}
+
+// recount moves one occurrence from the old hashed leaf to the new one of
+// a tree built WithMultiplicities, either being nil for none, replacing
+// their annotated leaves and rebuilding the tree. The annotated leaves of
+// leaves whose count drops to zero are removed altogether.
+// It returns ErrLeafNotFound if old is not part of the tree.
+func (t *Tree) recount(old, new []byte) error {
+	if old != nil && t.counts[string(old)] == 0 {
+		return ErrLeafNotFound
+	}
+	if bytes.Equal(old, new) {
+		return nil
+	}
+	t.expand()
+	defer t.c.lock()()
+
+	changed := [][]byte{}
+	for _, hl := range [][]byte{old, new} {
+		if hl != nil {
+			changed = append(changed, hl)
+		}
+	}
+	stale := make(map[string]bool, len(changed))
+	for _, hl := range changed {
+		if count := t.counts[string(hl)]; count > 0 {
+			stale[string(multiplicityLeaf(t.h, hl, count))] = true
+		}
+	}
+	leaves := make(Nodes, 0, len(t.leaves)+1)
+	for _, l := range t.leaves {
+		if !stale[string(l.val)] {
+			leaves = append(leaves, l)
+		}
+	}
+
+	if old != nil {
+		if t.counts[string(old)]--; t.counts[string(old)] == 0 {
+			delete(t.counts, string(old))
+		}
+	}
+	if new != nil {
+		t.counts[string(new)]++
+	}
+	added := make([][]byte, 0, len(changed))
+	for _, hl := range changed {
+		if count, ok := t.counts[string(hl)]; ok {
+			annotated := multiplicityLeaf(t.h, hl, count)
+			leaves = append(leaves, newNode(annotated))
+			added = append(added, annotated)
+		}
+	}
+	t.c.sortNodes(leaves)
+	t.leaves, t.size = leaves, len(leaves)
+	for _, hl := range added {
+		t.track(hl)
+	}
+	t.root = buildTree(t.h, t.leaves, nil, t.c, true, 0)
+	t.compacted = nil
+	t.commitSize()
+	return nil
+}
@@ -0,0 +1,92 @@
+package merkle
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTree_ProveMultiplicity(t *testing.T) {
+	leaves := hashStringSlice(algo, "b", "a", "c", "a", "b", "a")
+	tree := NewTree(algo, leaves, WithMultiplicities())
+
+	t.Run("Should Collapse Equal Leaves", func(t *testing.T) {
+		if len(tree.leaves) != 3 {
+			t.Errorf("expected 3 leaves, got %d", len(tree.leaves))
+		}
+	})
+
+	t.Run("Should Prove Multiplicity Of Each Leaf", func(t *testing.T) {
+		for leaf, exp := range map[string]int{"a": 3, "b": 2, "c": 1} {
+			hl := hashStringSlice(algo, leaf)[0]
+			proof, count, err := tree.ProveMultiplicity(hl)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if count != exp {
+				t.Errorf("expected leaf %s to occur %d times, got %d", leaf, exp, count)
+			}
+			if !VerifyMultiplicity(algo, hl, count, tree.Root().Bytes(), proof.ToByteArrays()) {
+				t.Errorf("multiplicity proof for leaf %s should have been valid", leaf)
+			}
+			if VerifyMultiplicity(algo, hl, count+1, tree.Root().Bytes(), proof.ToByteArrays()) {
+				t.Errorf("multiplicity proof for leaf %s should have been invalid with a wrong count", leaf)
+			}
+		}
+	})
+
+	t.Run("Should Not Collapse Leaves Without The Option", func(t *testing.T) {
+		if exp := NewTree(algo, leaves); bytes.Equal(exp.Root().Bytes(), tree.Root().Bytes()) {
+			t.Errorf("expected merkle roots to differ")
+		}
+	})
+
+	t.Run("With Non Existent Leaf Should Return ErrLeafNotFound", func(t *testing.T) {
+		if _, _, err := tree.ProveMultiplicity(hashStringSlice(algo, "d")[0]); err != ErrLeafNotFound {
+			t.Errorf("expected ErrLeafNotFound, got %v", err)
+		}
+	})
+}
+
+func TestTree_InsertMultiplicity(t *testing.T) {
+	leaves := hashStringSlice(algo, "b", "a", "c", "a")
+
+	t.Run("Should Count Inserted Leaves", func(t *testing.T) {
+		tree := NewTree(algo, leaves, WithMultiplicities())
+		for _, l := range hashStringSlice(algo, "a", "d") {
+			tree.Insert(l)
+		}
+		exp := NewTree(algo, hashStringSlice(algo, "b", "a", "c", "a", "a", "d"), WithMultiplicities())
+		if !bytes.Equal(tree.Root().Bytes(), exp.Root().Bytes()) {
+			t.Errorf("expected merkle root to be %s, got %s", exp.Root(), tree.Root())
+		}
+		hl := hashStringSlice(algo, "a")[0]
+		proof, count, err := tree.ProveMultiplicity(hl)
+		if err != nil || count != 3 {
+			t.Fatalf("expected leaf a to occur 3 times, got %d, %v", count, err)
+		}
+		if !VerifyMultiplicity(algo, hl, count, tree.Root().Bytes(), proof.ToByteArrays()) {
+			t.Errorf("multiplicity proof for leaf a should have been valid")
+		}
+	})
+
+	t.Run("Should Move Updated Occurrences", func(t *testing.T) {
+		tree := NewTree(algo, leaves, WithMultiplicities())
+		a, b, c := leaves[1], leaves[0], leaves[2]
+		if err := tree.Update(a, c); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if err := tree.Update(b, c); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		exp := NewTree(algo, hashStringSlice(algo, "c", "a", "c", "c"), WithMultiplicities())
+		if !bytes.Equal(tree.Root().Bytes(), exp.Root().Bytes()) {
+			t.Errorf("expected merkle root to be %s, got %s", exp.Root(), tree.Root())
+		}
+		if _, _, err := tree.ProveMultiplicity(b); err != ErrLeafNotFound {
+			t.Errorf("expected ErrLeafNotFound for a leaf no longer occurring, got %v", err)
+		}
+		if err := tree.Update(b, a); err != ErrLeafNotFound {
+			t.Errorf("expected ErrLeafNotFound, got %v", err)
+		}
+	})
+}
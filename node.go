@@ -2,6 +2,7 @@ package merkle
 
 import (
 	"bytes"
+	"encoding/hex"
 	"fmt"
 	"io"
 
@@ -41,6 +42,11 @@ func (n *Node) IsLeaf() bool {
 	return n.left == nil && n.right == nil
 }
 
+// IsRoot tells whether the Node is the root of its tree, having no parent.
+func (n *Node) IsRoot() bool {
+	return n.parent == nil
+}
+
 // IsLeft tells whether the Node is a left child of its parent.
 func (n *Node) IsLeft() bool {
 	return n.parent != nil && n.parent.left == n
@@ -51,6 +57,46 @@ func (n *Node) IsRight() bool {
 	return n.parent != nil && n.parent.right == n
 }
 
+// Leaves returns the leaves under the Node from left to right, that is, the
+// leaves the Node commits to. A leaf returns itself. Nodes paired with
+// themselves, as ModeBitcoin does with odd ones, are collected once.
+func (n *Node) Leaves() Nodes {
+	leaves := Nodes{}
+	var collect func(n *Node)
+	collect = func(n *Node) {
+		if n == nil {
+			return
+		}
+		if n.IsLeaf() {
+			leaves = append(leaves, n)
+			return
+		}
+		collect(n.left)
+		if n.right != n.left {
+			collect(n.right)
+		}
+	}
+	collect(n)
+	return leaves
+}
+
+// LeafCount returns the number of leaves under the Node, that is, its
+// weight, counting them the same way Leaves does without collecting them.
+// A leaf counts itself, a nil Node counts none.
+func (n *Node) LeafCount() int {
+	if n == nil {
+		return 0
+	}
+	if n.IsLeaf() {
+		return 1
+	}
+	count := n.left.LeafCount()
+	if n.right != n.left {
+		count += n.right.LeafCount()
+	}
+	return count
+}
+
 // Sibling returns its opposite sibling.
 // Given 2 nodes i, j if Node is i returns j else returns i.
 // Returns nil if root.
@@ -64,6 +110,16 @@ func (n *Node) Sibling() *Node {
 	return n.parent.left
 }
 
+// Ancestors returns the chain of Nodes from its parent up to the root,
+// that is, [parent, grandparent, ..., root]. Returns an empty Nodes if root.
+func (n *Node) Ancestors() Nodes {
+	ancestors := Nodes{}
+	for p := n.parent; p != nil; p = p.parent {
+		ancestors = append(ancestors, p)
+	}
+	return ancestors
+}
+
 // Graphify builds up a hierarchical graphic representation
 // from the Node to the very bottom of its children.
 // Will write to the provided io.Writer for greater usability.
@@ -92,6 +148,50 @@ func (n *Node) Graphify(w io.Writer) {
 	w.Write(branches[n.Hex()].Bytes())
 }
 
+// GraphifyStream writes a hierarchical graphic representation alike
+// Graphify, with children in their left to right order rather than
+// sorted, but it writes each Node as it walks down the tree rather
+// than building the whole representation in memory beforehand, thus
+// memory is bounded to the depth of the tree, which suits huge trees.
+// Hashes are truncated to their first truncate hex characters,
+// a truncate lower than 1 writes them in full.
+// Returns the first error, if any, the io.Writer returned.
+func (n *Node) GraphifyStream(w io.Writer, truncate int) error {
+	label := func(n *Node) string {
+		h := n.Hex()
+		if truncate > 0 && len(h) > truncate {
+			h = h[:truncate]
+		}
+		return h
+	}
+
+	var write func(n *Node, prefix string) error
+	write = func(n *Node, prefix string) error {
+		children := Nodes{n.left, n.right}
+		for i, c := range children {
+			if c == nil {
+				continue
+			}
+			branch, indent := "├── ", "│   "
+			if i == len(children)-1 || children[i+1] == nil {
+				branch, indent = "└── ", "    "
+			}
+			if _, err := io.WriteString(w, prefix+branch+label(c)+"\n"); err != nil {
+				return err
+			}
+			if err := write(c, prefix+indent); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if _, err := io.WriteString(w, label(n)+"\n"); err != nil {
+		return err
+	}
+	return write(n, "")
+}
+
 // WalkPreOrder traverses from the tree *Node down
 // to the very bottom using the "Pre Order" strategy.
 func (n *Node) WalkPreOrder(fn func(n *Node, depth int)) {
@@ -151,6 +251,8 @@ func (ns Nodes) IterateSortedPair(fn func(i, j *Node)) (odd *Node) {
 }
 
 // ToHexStrings converts each Node in Nodes into an hex strings.
+// The order of Nodes is kept, which for proofs is from the leaf up to
+// the root, that is, the order siblings are folded in by Verify.
 func (ns Nodes) ToHexStrings() []string {
 	hexs := make([]string, 0, len(ns))
 	for _, n := range ns {
@@ -159,7 +261,32 @@ func (ns Nodes) ToHexStrings() []string {
 	return hexs
 }
 
+// ToHexStringsReversed converts each Node in Nodes into an hex strings
+// same as ToHexStrings does, but in reversed order, which for proofs is
+// from the root down to the leaf, as expected by some verifiers.
+func (ns Nodes) ToHexStringsReversed() []string {
+	return ns.Reversed().ToHexStrings()
+}
+
+// Reverse reverses the order of Nodes in place, which for
+// proofs flips them from the root down to the leaf or vice versa.
+func (ns Nodes) Reverse() {
+	for i, j := 0, len(ns)-1; i < j; i, j = i+1, j-1 {
+		ns[i], ns[j] = ns[j], ns[i]
+	}
+}
+
+// Reversed returns a copy of Nodes in reversed order, same as
+// Reverse does, leaving Nodes untouched.
+func (ns Nodes) Reversed() Nodes {
+	rev := make(Nodes, len(ns))
+	copy(rev, ns)
+	rev.Reverse()
+	return rev
+}
+
 // ToByteArrays converts each Node in Nodes into a slice of byte array.
+// The order of Nodes is kept, same as ToHexStrings does.
 func (ns Nodes) ToByteArrays() [][]byte {
 	barr := make([][]byte, 0, len(ns))
 	for _, n := range ns {
@@ -168,6 +295,27 @@ func (ns Nodes) ToByteArrays() [][]byte {
 	return barr
 }
 
+// NodesFromHex converts each hex string into a Node, which is the inverse
+// of ToHexStrings, e.g. to rehydrate a proof serialized as hex strings.
+// Returns an error if any of the provided strings is not valid hex.
+func NodesFromHex(hexs ...string) (Nodes, error) {
+	ns := make(Nodes, 0, len(hexs))
+	for _, h := range hexs {
+		b, err := hex.DecodeString(h)
+		if err != nil {
+			return nil, err
+		}
+		ns = append(ns, newNode(b))
+	}
+	return ns, nil
+}
+
+// NodesFromBytes converts each byte array into a Node,
+// which is the inverse of ToByteArrays.
+func NodesFromBytes(bs ...[]byte) Nodes {
+	return byteArrSliceToNodes(bs...)
+}
+
 // newNode makes and return a new *Node
 // with the provided hash set as val.
 func newNode(h []byte) *Node {
@@ -64,6 +64,43 @@ func TestNode_Sibling(t *testing.T) {
 	})
 }
 
+func TestNode_Ancestors(t *testing.T) {
+	leaf := oddLeavesTree.leaves[0]
+
+	t.Run("Should Return Chain Up To Root", func(t *testing.T) {
+		ancestors := leaf.Ancestors()
+		if len(ancestors) != 3 {
+			t.Fatalf("expected 3 ancestors, got %d", len(ancestors))
+		}
+		if ancestors[0] != leaf.parent || ancestors[2] != oddLeavesTree.Root() {
+			t.Errorf("expected ancestors to go from parent to root")
+		}
+	})
+
+	t.Run("Should Return Empty For Root", func(t *testing.T) {
+		if ancestors := oddLeavesTree.Root().Ancestors(); len(ancestors) != 0 {
+			t.Errorf("expected no ancestors, got %d", len(ancestors))
+		}
+	})
+}
+
+func TestNode_IsRoot(t *testing.T) {
+	root := &Node{val: []byte("root")}
+	child := &Node{val: []byte("child"), parent: root}
+
+	t.Run("Should Return True", func(t *testing.T) {
+		if !root.IsRoot() {
+			t.Errorf("expected to return true")
+		}
+	})
+
+	t.Run("Should Return False", func(t *testing.T) {
+		if child.IsRoot() {
+			t.Errorf("expected to return false")
+		}
+	})
+}
+
 func TestNode_IsLeaf(t *testing.T) {
 
 	leaf := &Node{val: []byte("leaf")}
@@ -318,6 +355,68 @@ func TestNodes_ToHexStrings(t *testing.T) {
 	}
 }
 
+func TestNodes_ToHexStringsReversed(t *testing.T) {
+	nodes := byteArrSliceToNodes(hashStringSlice(sha256.New(), "a", "b", "c")...)
+	expHex := []string{
+		"2e7d2c03a9507ae265ecf5b5356885a53393a2029d241394997265a1a25aefc6",
+		"3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d",
+		"ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb",
+	}
+	actHex := nodes.ToHexStringsReversed()
+	for i, exp := range expHex {
+		if actHex[i] != exp {
+			t.Errorf("expected hex at %d to be %s, got %s", i, exp, actHex)
+		}
+	}
+}
+
+func TestNodes_Reverse(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d")
+
+	t.Run("Should Reverse In Place", func(t *testing.T) {
+		for n := 0; n <= len(hl); n++ {
+			nodes := byteArrSliceToNodes(hl[:n]...)
+			nodes.Reverse()
+			for i, node := range nodes {
+				if !bytes.Equal(node.val, hl[n-1-i]) {
+					t.Errorf("expected node at %d of %d to be %x, got %s", i, n, hl[n-1-i], node)
+				}
+			}
+		}
+	})
+
+	t.Run("Should Return Reversed Copy", func(t *testing.T) {
+		nodes := byteArrSliceToNodes(hl...)
+		rev := nodes.Reversed()
+		if rev[0] != nodes[3] || rev[3] != nodes[0] {
+			t.Errorf("expected nodes to be reversed")
+		}
+		if !bytes.Equal(nodes[0].val, hl[0]) {
+			t.Errorf("expected nodes to be left untouched")
+		}
+	})
+}
+
+func TestNodes_ProofOrder(t *testing.T) {
+	// leaves are sorted as : 18ac.., 2e7d.., 3e23.., 3f79.., ca97..
+	leaf := oddLeavesTree.leaves[0]
+	proof := oddLeavesTree.Proof(leaf.val)
+
+	t.Run("Should Go From Leaf To Root", func(t *testing.T) {
+		hexs := proof.ToHexStrings()
+		if hexs[0] != oddLeavesTree.leaves[1].Hex() || hexs[len(hexs)-1] != oddLeavesTree.leaves[4].Hex() {
+			t.Errorf("expected proof to go from the leaf sibling up to the root child, got %v", hexs)
+		}
+	})
+
+	t.Run("Should Go From Root To Leaf Reversed", func(t *testing.T) {
+		hexs := proof.ToHexStringsReversed()
+		if hexs[0] != oddLeavesTree.leaves[4].Hex() || hexs[len(hexs)-1] != oddLeavesTree.leaves[1].Hex() {
+			t.Errorf("expected reversed proof to go from the root child down to the leaf sibling, got %v", hexs)
+		}
+	})
+}
+
 func TestNodes_ToByteArrays(t *testing.T) {
 	nodes := Nodes{
 		&Node{val: []byte("a")},
@@ -331,3 +430,157 @@ func TestNodes_ToByteArrays(t *testing.T) {
 		}
 	}
 }
+
+func TestNodesFromHex(t *testing.T) {
+	exp := oddLeavesTree.leaves
+
+	t.Run("Should Round Trip ToHexStrings", func(t *testing.T) {
+		act, err := NodesFromHex(exp.ToHexStrings()...)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		for i := range exp {
+			if !bytes.Equal(act[i].val, exp[i].val) {
+				t.Errorf("expected node at index %d to be %s, got %s", i, exp[i], act[i])
+			}
+		}
+	})
+
+	t.Run("Should Fail With Bad Hex", func(t *testing.T) {
+		if _, err := NodesFromHex("ab", "zz"); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+}
+
+func TestNodesFromBytes(t *testing.T) {
+	exp := oddLeavesTree.leaves
+	act := NodesFromBytes(exp.ToByteArrays()...)
+	for i := range exp {
+		if !bytes.Equal(act[i].val, exp[i].val) {
+			t.Errorf("expected node at index %d to be %s, got %s", i, exp[i], act[i])
+		}
+	}
+}
+
+func TestNode_GraphifyStream(t *testing.T) {
+	t.Run("Should Write Whole Hashes", func(t *testing.T) {
+		exp := `3a64c13ffc8d22739538f49d901d909754e4ca185cf128ce7e64c8482f0cd8c6
+├── a26df13b366b0fc0e7a96ec9a1658d691d7640668de633333098d7952ce0c50b
+│   ├── 28b5a66c8c61ee13ad5f708a561d758b24d10abe5a0e72133c85d59821539e05
+│   │   ├── 3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d
+│   │   └── 3f79bb7b435b05321651daefd374cdc681dc06faa65e374e38337b88ca046dea
+│   └── 800e03ddb2432933692401d1631850c0af91953fd9c8f3874488c0541dfcf413
+│       ├── 18ac3e7343f016890c510e93f935261169d9e3f565436429830faf0934f4f8e4
+│       └── 2e7d2c03a9507ae265ecf5b5356885a53393a2029d241394997265a1a25aefc6
+└── ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb
+`
+		act := strings.Builder{}
+		if err := oddLeavesTree.Root().GraphifyStream(&act, 0); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if act.String() != exp {
+			t.Errorf("expected streamed tree to be : \n %s \n got \n %s", exp, act.String())
+		}
+	})
+
+	t.Run("Should Truncate Hashes", func(t *testing.T) {
+		exp := `3a64c1
+├── a26df1
+│   ├── 28b5a6
+│   │   ├── 3e23e8
+│   │   └── 3f79bb
+│   └── 800e03
+│       ├── 18ac3e
+│       └── 2e7d2c
+└── ca9781
+`
+		act := strings.Builder{}
+		if err := oddLeavesTree.Root().GraphifyStream(&act, 6); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if act.String() != exp {
+			t.Errorf("expected streamed tree to be : \n %s \n got \n %s", exp, act.String())
+		}
+	})
+}
+
+func TestNode_Leaves(t *testing.T) {
+	t.Run("Should Return All Leaves From Root", func(t *testing.T) {
+		// inner pairs being sorted, the left to right order
+		// of the leaves may differ from their sorted order.
+		exp := []string{
+			"3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d",
+			"3f79bb7b435b05321651daefd374cdc681dc06faa65e374e38337b88ca046dea",
+			"18ac3e7343f016890c510e93f935261169d9e3f565436429830faf0934f4f8e4",
+			"2e7d2c03a9507ae265ecf5b5356885a53393a2029d241394997265a1a25aefc6",
+			"ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb",
+		}
+		act := oddLeavesTree.Root().Leaves().ToHexStrings()
+		if len(act) != len(exp) {
+			t.Fatalf("expected %d leaves, got %d", len(exp), len(act))
+		}
+		for i := range exp {
+			if act[i] != exp[i] {
+				t.Errorf("expected leaf at index %d to be %s, got %s", i, exp[i], act[i])
+			}
+		}
+	})
+
+	t.Run("Should Return Subtree Leaves Left To Right", func(t *testing.T) {
+		inner := oddLeavesTree.Root().left
+		exp := []string{
+			"3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d",
+			"3f79bb7b435b05321651daefd374cdc681dc06faa65e374e38337b88ca046dea",
+			"18ac3e7343f016890c510e93f935261169d9e3f565436429830faf0934f4f8e4",
+			"2e7d2c03a9507ae265ecf5b5356885a53393a2029d241394997265a1a25aefc6",
+		}
+		act := inner.Leaves().ToHexStrings()
+		if len(act) != len(exp) {
+			t.Fatalf("expected %d leaves, got %d", len(exp), len(act))
+		}
+		for i := range exp {
+			if act[i] != exp[i] {
+				t.Errorf("expected leaf at index %d to be %s, got %s", i, exp[i], act[i])
+			}
+		}
+	})
+
+	t.Run("Should Return Itself For A Leaf", func(t *testing.T) {
+		leaf := oddLeavesTree.leaves[0]
+		if act := leaf.Leaves(); len(act) != 1 || act[0] != leaf {
+			t.Errorf("expected leaf to return itself")
+		}
+	})
+
+	t.Run("Should Collect Duplicated Nodes Once", func(t *testing.T) {
+		tree := NewTree(sha256.New(), hashStringSlice(sha256.New(), "a", "b", "c"), WithMode(ModeBitcoin))
+		if act := tree.Root().Leaves(); len(act) != 3 {
+			t.Errorf("expected 3 leaves, got %d", len(act))
+		}
+	})
+}
+
+func TestNode_LeafCount(t *testing.T) {
+	t.Run("Should Count Leaves Under Node", func(t *testing.T) {
+		for _, n := range []*Node{oddLeavesTree.Root(), oddLeavesTree.Root().left, oddLeavesTree.leaves[0]} {
+			if exp, act := len(n.Leaves()), n.LeafCount(); act != exp {
+				t.Errorf("expected %d leaves under %s, got %d", exp, n, act)
+			}
+		}
+	})
+
+	t.Run("Should Count Nodes Paired With Themselves Once", func(t *testing.T) {
+		tree := NewTree(algo, hashStringSlice(algo, "a", "b", "c", "d", "e"), WithMode(ModeBitcoin))
+		if act := tree.Root().LeafCount(); act != 5 {
+			t.Errorf("expected 5 leaves, got %d", act)
+		}
+	})
+
+	t.Run("Should Count No Leaves Under Nil Node", func(t *testing.T) {
+		var n *Node
+		if act := n.LeafCount(); act != 0 {
+			t.Errorf("expected 0 leaves, got %d", act)
+		}
+	})
+}
@@ -0,0 +1,359 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// ErrOpenZeppelin is returned when either a tree can't be represented in
+// the OpenZeppelin merkle-tree JSON format or the JSON isn't compatible.
+var ErrOpenZeppelin = errors.New("merkle: incompatible OpenZeppelin merkle tree")
+
+// the OpenZeppelin dump formats, SimpleMerkleTree values are the very
+// leaves while StandardMerkleTree values are ABI encoded and hashed twice.
+const (
+	ozSimpleFormat   = "simple-v1"
+	ozStandardFormat = "standard-v1"
+)
+
+// ozJSON is the JSON representation of an OpenZeppelin merkle tree.
+type ozJSON struct {
+	Format       string    `json:"format"`
+	Tree         []string  `json:"tree"`
+	Values       []ozValue `json:"values"`
+	LeafEncoding []string  `json:"leafEncoding,omitempty"`
+}
+
+// ozValue is a value alongside the index of its leaf within the ozJSON
+// tree, the value is the hex leaf itself in the simple format and the
+// list of values to ABI encode in the standard one.
+type ozValue struct {
+	Value     interface{} `json:"value"`
+	TreeIndex int         `json:"treeIndex"`
+}
+
+// ToOpenZeppelinJSON marshals the tree into the JSON dump of the
+// OpenZeppelin @openzeppelin/merkle-tree SimpleMerkleTree, so that it
+// can be loaded by the JS library with SimpleMerkleTree.load.
+//
+// OpenZeppelin lays trees out as complete binary trees and pairs sorted
+// hashes same as ModeSorted does, which yields the same merkle root for
+// power of two numbers of leaves and few others only, e.g. 3.
+// ErrOpenZeppelin is returned whenever the roots would differ.
+// The tree is expected to be built with sha3.NewLegacyKeccak256, from
+// golang.org/x/crypto, to be verified on-chain.
+func (t Tree) ToOpenZeppelinJSON() ([]byte, error) {
+	if t.c.withoutLeaves {
+		return nil, ErrNoLeaves
+	}
+	tree, err := t.ozLayout()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]ozValue, len(t.leaves))
+	for i, l := range t.leaves {
+		values[i] = ozValue{Value: ozHex(l.val), TreeIndex: len(tree) - 1 - i}
+	}
+	return marshalOZ(ozJSON{Format: ozSimpleFormat, Values: values}, tree)
+}
+
+// ozLayout lays the tree out as OpenZeppelin does, that is, leaves at the
+// end of the tree in reversed order and every parent at i having its
+// children at 2i+1 and 2i+2. It returns ErrOpenZeppelin if the tree
+// is not in ModeSorted or its root differs from the laid out one.
+func (t Tree) ozLayout() ([][]byte, error) {
+	if t.c.mode != ModeSorted {
+		return nil, ErrOpenZeppelin
+	}
+	n := len(t.leaves)
+	if n == 0 {
+		return nil, ErrOpenZeppelin
+	}
+	tree := make([][]byte, 2*n-1)
+	for i, l := range t.leaves {
+		tree[len(tree)-1-i] = l.val
+	}
+	defer t.c.lock()()
+	for k := n - 2; k >= 0; k-- {
+		l, r := t.c.order(tree[2*k+1], tree[2*k+2])
+		tree[k] = t.c.combine(t.h, l, r, 0)
+	}
+	if !bytes.Equal(tree[0], t.root.val) {
+		return nil, ErrOpenZeppelin
+	}
+	return tree, nil
+}
+
+// marshalOZ marshals the provided dump along with the hex encoded tree.
+func marshalOZ(oz ozJSON, tree [][]byte) ([]byte, error) {
+	oz.Tree = make([]string, len(tree))
+	for k, h := range tree {
+		oz.Tree[k] = ozHex(h)
+	}
+	return json.Marshal(oz)
+}
+
+// FromOpenZeppelinJSON builds up a new merkle tree with the provided
+// hashing algorithm, e.g. sha3.NewLegacyKeccak256, out of the values of
+// either an OpenZeppelin SimpleMerkleTree or StandardMerkleTree JSON dump.
+// The leaves of the latter are hashed as StandardLeaf does, which is
+// keccak256 regardless of h. ErrOpenZeppelin is returned if the dump format or its
+// leaf encoding is not supported, it has no values or any of its leaves,
+// or its root, differs from the built tree ones.
+func FromOpenZeppelinJSON(h hash.Hash, data []byte, opts ...Option) (*Tree, error) {
+	oz, err := unmarshalOZ(data)
+	if err != nil {
+		return nil, err
+	}
+	tree := make([][]byte, len(oz.Tree))
+	for k, s := range oz.Tree {
+		if tree[k], err = hex.DecodeString(strings.TrimPrefix(s, "0x")); err != nil {
+			return nil, err
+		}
+	}
+	hl := make([][]byte, len(oz.Values))
+	for i, v := range oz.Values {
+		if hl[i], err = oz.leaf(v); err != nil {
+			return nil, err
+		}
+		if v.TreeIndex < 0 || v.TreeIndex >= len(tree) || !bytes.Equal(tree[v.TreeIndex], hl[i]) {
+			return nil, ErrOpenZeppelin
+		}
+	}
+
+	t := NewTree(h, hl, opts...)
+	if !bytes.Equal(t.root.val, tree[0]) {
+		return nil, ErrOpenZeppelin
+	}
+	return t, nil
+}
+
+// unmarshalOZ unmarshals the provided dump, keeping numbers as json.Number
+// so that big integers are not rounded. It returns ErrOpenZeppelin if the
+// format is not supported or the dump has no values.
+func unmarshalOZ(data []byte) (*ozJSON, error) {
+	var oz ozJSON
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&oz); err != nil {
+		return nil, err
+	}
+	if oz.Format != ozSimpleFormat && oz.Format != ozStandardFormat {
+		return nil, ErrOpenZeppelin
+	}
+	if len(oz.Values) == 0 || len(oz.Tree) == 0 {
+		return nil, ErrOpenZeppelin
+	}
+	return &oz, nil
+}
+
+// leaf returns the leaf hash of the provided value of the dump.
+func (oz ozJSON) leaf(v ozValue) ([]byte, error) {
+	if oz.Format == ozStandardFormat {
+		value, ok := v.Value.([]interface{})
+		if !ok {
+			return nil, ErrOpenZeppelin
+		}
+		return StandardLeaf(oz.LeafEncoding, value)
+	}
+	s, ok := v.Value.(string)
+	if !ok {
+		return nil, ErrOpenZeppelin
+	}
+	return hex.DecodeString(strings.TrimPrefix(s, "0x"))
+}
+
+// StandardTree is a merkle tree compatible with the OpenZeppelin
+// StandardMerkleTree, whose leaves are the StandardLeaf of each value,
+// and whose pairs are hashed sorted with keccak256, as verified on-chain
+// by the OpenZeppelin MerkleProof library. The leaves of a value are
+// proven with Proof(StandardLeaf(encoding, value)).
+type StandardTree struct {
+	*Tree
+	encoding []string
+	values   [][]interface{}
+}
+
+// NewStandardTree makes a new StandardTree out of the provided values, each
+// of them being ABI encoded as the leafEncoding types, e.g. "address" and
+// "uint256", see StandardLeaf. Values are kept as provided, which is what
+// ToOpenZeppelinJSON dumps. The tree is built in ModeSorted.
+func NewStandardTree(leafEncoding []string, values [][]interface{}) (*StandardTree, error) {
+	if len(values) == 0 {
+		return nil, ErrNoLeaves
+	}
+	hl := make([][]byte, len(values))
+	for i, v := range values {
+		l, err := StandardLeaf(leafEncoding, v)
+		if err != nil {
+			return nil, err
+		}
+		hl[i] = l
+	}
+	return &StandardTree{
+		Tree:     NewTree(sha3.NewLegacyKeccak256(), hl),
+		encoding: leafEncoding,
+		values:   values,
+	}, nil
+}
+
+// ToOpenZeppelinJSON marshals the tree into the JSON dump of the OpenZeppelin
+// StandardMerkleTree, so that it can be loaded by the JS library with
+// StandardMerkleTree.load. Same as Tree.ToOpenZeppelinJSON, ErrOpenZeppelin
+// is returned whenever the OpenZeppelin layout would yield another root.
+func (t StandardTree) ToOpenZeppelinJSON() ([]byte, error) {
+	tree, err := t.ozLayout()
+	if err != nil {
+		return nil, err
+	}
+	// values are dumped in the provided order pointing to their leaf, which
+	// is the i-th of the sorted leaves, equal leaves being interchangeable.
+	order := make([]int, len(t.values))
+	hl := make([][]byte, len(t.values))
+	for i, v := range t.values {
+		order[i] = i
+		hl[i], _ = StandardLeaf(t.encoding, v)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return t.c.less(hl[order[i]], hl[order[j]])
+	})
+	values := make([]ozValue, len(t.values))
+	for i, v := range order {
+		values[v] = ozValue{Value: t.values[v], TreeIndex: len(tree) - 1 - i}
+	}
+	return marshalOZ(ozJSON{Format: ozStandardFormat, Values: values, LeafEncoding: t.encoding}, tree)
+}
+
+// StandardLeaf returns the leaf hash the OpenZeppelin StandardMerkleTree
+// makes of the provided value, that is, keccak256(keccak256(abi.encode(v)))
+// with v ABI encoded as the leafEncoding types.
+//
+// The static types address, bool, bytes1 up to bytes32, intN and uintN are
+// supported, whose values are either strings, as found in JSON dumps, bools,
+// json.Number, int or *big.Int. Integers strings are either decimal or 0x
+// prefixed hexadecimal. ErrOpenZeppelin is returned, wrapped with the
+// reason, for unsupported types as well as for values not fitting theirs.
+func StandardLeaf(leafEncoding []string, value []interface{}) ([]byte, error) {
+	if len(leafEncoding) != len(value) {
+		return nil, fmt.Errorf("%w: %d values for %d types", ErrOpenZeppelin, len(value), len(leafEncoding))
+	}
+	encoded := make([]byte, 32*len(value))
+	for i, typ := range leafEncoding {
+		if err := abiEncode(encoded[32*i:32*i+32], typ, value[i]); err != nil {
+			return nil, err
+		}
+	}
+	h := sha3.NewLegacyKeccak256()
+	h.Write(encoded)
+	leaf := h.Sum(nil)
+	h.Reset()
+	h.Write(leaf)
+	return h.Sum(nil), nil
+}
+
+// abiEncode ABI encodes the provided value of a static type into word.
+func abiEncode(word []byte, typ string, v interface{}) error {
+	switch {
+	case typ == "address":
+		b, err := abiHex(v)
+		if err != nil || len(b) != 20 {
+			return fmt.Errorf("%w: invalid address %v", ErrOpenZeppelin, v)
+		}
+		copy(word[12:], b)
+	case typ == "bool":
+		b, ok := v.(bool)
+		if s, isString := v.(string); isString {
+			b, ok = s == "true", s == "true" || s == "false"
+		}
+		if !ok {
+			return fmt.Errorf("%w: invalid bool %v", ErrOpenZeppelin, v)
+		}
+		if b {
+			word[31] = 1
+		}
+	case strings.HasPrefix(typ, "bytes"):
+		n, err := strconv.Atoi(strings.TrimPrefix(typ, "bytes"))
+		if err != nil || n < 1 || n > 32 {
+			return fmt.Errorf("%w: unsupported type %q", ErrOpenZeppelin, typ)
+		}
+		b, err := abiHex(v)
+		if err != nil || len(b) != n {
+			return fmt.Errorf("%w: invalid %s %v", ErrOpenZeppelin, typ, v)
+		}
+		copy(word, b)
+	case strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "int"):
+		signed := strings.HasPrefix(typ, "int")
+		bitSize := 256
+		if s := strings.TrimPrefix(strings.TrimPrefix(typ, "u"), "int"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 8 || n > 256 || n%8 != 0 {
+				return fmt.Errorf("%w: unsupported type %q", ErrOpenZeppelin, typ)
+			}
+			bitSize = n
+		}
+		x, ok := abiInt(v)
+		if !ok || !abiFits(x, bitSize, signed) {
+			return fmt.Errorf("%w: invalid %s %v", ErrOpenZeppelin, typ, v)
+		}
+		if x.Sign() < 0 {
+			// two's complement over the whole word.
+			x = new(big.Int).Add(x, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		x.FillBytes(word)
+	default:
+		return fmt.Errorf("%w: unsupported type %q", ErrOpenZeppelin, typ)
+	}
+	return nil
+}
+
+// abiHex decodes the provided 0x prefixed hexadecimal string.
+func abiHex(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok || !strings.HasPrefix(s, "0x") {
+		return nil, ErrOpenZeppelin
+	}
+	return hex.DecodeString(s[2:])
+}
+
+// abiInt converts the provided value into an integer, reporting whether
+// it's an integer at all.
+func abiInt(v interface{}) (*big.Int, bool) {
+	switch x := v.(type) {
+	case *big.Int:
+		return x, x != nil
+	case int:
+		return big.NewInt(int64(x)), true
+	case json.Number:
+		return new(big.Int).SetString(x.String(), 10)
+	case string:
+		if strings.HasPrefix(x, "0x") {
+			return new(big.Int).SetString(x[2:], 16)
+		}
+		return new(big.Int).SetString(x, 10)
+	}
+	return nil, false
+}
+
+// abiFits tells whether x fits an integer of the provided bit size.
+func abiFits(x *big.Int, bitSize int, signed bool) bool {
+	if !signed {
+		return x.Sign() >= 0 && x.BitLen() <= bitSize
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bitSize-1))
+	return x.Cmp(limit) < 0 && x.Cmp(new(big.Int).Neg(limit)) >= 0
+}
+
+// ozHex encodes the provided hash as a 0x prefixed hexadecimal string.
+func ozHex(h []byte) string {
+	return "0x" + hex.EncodeToString(h)
+}
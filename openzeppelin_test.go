@@ -0,0 +1,196 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// ozStandardFixture is the StandardMerkleTree.dump of the values of the
+// @openzeppelin/merkle-tree README example, whose documented root is
+// 0xd4dee0be...bd77 and documented proof of the first value is [0xb92c48e9...].
+const ozStandardFixture = `{"format":"standard-v1","tree":["0xd4dee0beab2d53f2cc83e567171bd2820e49898130a22622b10ead383e90bd77","0xeb02c421cfa48976e66dfb29120745909ea3a0f843456c263cf8f1253483e283","0xb92c48e9d7abe27fd8dfd6b5dfdbfb1c9a463f80c712b66f3a5180a090cccafc"],"values":[{"value":["0x1111111111111111111111111111111111111111","5000000000000000000"],"treeIndex":1},{"value":["0x2222222222222222222222222222222222222222","2500000000000000000"],"treeIndex":2}],"leafEncoding":["address","uint256"]}`
+
+var ozStandardValues = [][]interface{}{
+	{"0x1111111111111111111111111111111111111111", "5000000000000000000"},
+	{"0x2222222222222222222222222222222222222222", "2500000000000000000"},
+}
+
+// ozFixture is the SimpleMerkleTree.dump of the leaves of the README example
+// StandardMerkleTree, in ascending order, whose layout is the very same,
+// hence whose root is the documented 0xd4dee0be...bd77 as well.
+const ozFixture = `{"format":"simple-v1","tree":["0xd4dee0beab2d53f2cc83e567171bd2820e49898130a22622b10ead383e90bd77","0xeb02c421cfa48976e66dfb29120745909ea3a0f843456c263cf8f1253483e283","0xb92c48e9d7abe27fd8dfd6b5dfdbfb1c9a463f80c712b66f3a5180a090cccafc"],"values":[{"value":"0xb92c48e9d7abe27fd8dfd6b5dfdbfb1c9a463f80c712b66f3a5180a090cccafc","treeIndex":2},{"value":"0xeb02c421cfa48976e66dfb29120745909ea3a0f843456c263cf8f1253483e283","treeIndex":1}]}`
+
+// ozRoot is the root documented by the @openzeppelin/merkle-tree README.
+const ozRoot = "d4dee0beab2d53f2cc83e567171bd2820e49898130a22622b10ead383e90bd77"
+
+func TestTree_ToOpenZeppelinJSON(t *testing.T) {
+	t.Run("Should Match Fixture", func(t *testing.T) {
+		hl := make([][]byte, len(ozStandardValues))
+		for i, v := range ozStandardValues {
+			hl[i], _ = StandardLeaf([]string{"address", "uint256"}, v)
+		}
+		b, err := NewTree(sha3.NewLegacyKeccak256(), hl).ToOpenZeppelinJSON()
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		var exp, act interface{}
+		json.Unmarshal([]byte(ozFixture), &exp)
+		json.Unmarshal(b, &act)
+		if !reflect.DeepEqual(exp, act) {
+			t.Errorf("expected %s, got %s", ozFixture, b)
+		}
+	})
+
+	t.Run("Should Return ErrOpenZeppelin For Differing Layouts", func(t *testing.T) {
+		if _, err := oddLeavesTree.ToOpenZeppelinJSON(); err != ErrOpenZeppelin {
+			t.Errorf("expected ErrOpenZeppelin, got %v", err)
+		}
+	})
+
+	t.Run("Should Return ErrOpenZeppelin Without Leaves", func(t *testing.T) {
+		empty := NewTendermintTree(algo, nil)
+		empty.c = newConfig()
+		if _, err := empty.ToOpenZeppelinJSON(); err != ErrOpenZeppelin {
+			t.Errorf("expected ErrOpenZeppelin, got %v", err)
+		}
+	})
+}
+
+func TestFromOpenZeppelinJSON(t *testing.T) {
+	t.Run("Should Load Fixture", func(t *testing.T) {
+		tree, err := FromOpenZeppelinJSON(sha3.NewLegacyKeccak256(), []byte(ozFixture))
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if act := tree.Root().String(); act != ozRoot {
+			t.Errorf("expected merkle root should have been %s, got %s", ozRoot, act)
+		}
+	})
+
+	t.Run("Should Return ErrOpenZeppelin For Unsupported Format", func(t *testing.T) {
+		var oz ozJSON
+		json.Unmarshal([]byte(ozFixture), &oz)
+		oz.Format = "standard-v1"
+		b, _ := json.Marshal(oz)
+		if _, err := FromOpenZeppelinJSON(sha3.NewLegacyKeccak256(), b); err != ErrOpenZeppelin {
+			t.Errorf("expected ErrOpenZeppelin, got %v", err)
+		}
+	})
+
+	t.Run("Should Return ErrOpenZeppelin For Mismatching Root", func(t *testing.T) {
+		var oz ozJSON
+		json.Unmarshal([]byte(ozFixture), &oz)
+		oz.Tree[0] = oz.Tree[1]
+		b, _ := json.Marshal(oz)
+		if _, err := FromOpenZeppelinJSON(sha3.NewLegacyKeccak256(), b); err != ErrOpenZeppelin {
+			t.Errorf("expected ErrOpenZeppelin, got %v", err)
+		}
+	})
+}
+
+func TestFromOpenZeppelinJSON_Standard(t *testing.T) {
+	t.Run("Should Load Fixture", func(t *testing.T) {
+		tree, err := FromOpenZeppelinJSON(sha3.NewLegacyKeccak256(), []byte(ozStandardFixture))
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if act := tree.Root().String(); act != ozRoot {
+			t.Errorf("expected merkle root should have been %s, got %s", ozRoot, act)
+		}
+		leaf, _ := StandardLeaf([]string{"address", "uint256"}, ozStandardValues[0])
+		proof := tree.Proof(leaf).ToHexStrings()
+		if len(proof) != 1 || proof[0] != "b92c48e9d7abe27fd8dfd6b5dfdbfb1c9a463f80c712b66f3a5180a090cccafc" {
+			t.Errorf("expected proof to be the documented one, got %v", proof)
+		}
+	})
+
+	t.Run("Should Return ErrOpenZeppelin For Mismatching Leaf", func(t *testing.T) {
+		var oz ozJSON
+		json.Unmarshal([]byte(ozStandardFixture), &oz)
+		oz.Values[0].Value = []interface{}{"0x1111111111111111111111111111111111111111", "1"}
+		b, _ := json.Marshal(oz)
+		if _, err := FromOpenZeppelinJSON(sha3.NewLegacyKeccak256(), b); err != ErrOpenZeppelin {
+			t.Errorf("expected ErrOpenZeppelin, got %v", err)
+		}
+	})
+
+	t.Run("Should Return ErrOpenZeppelin Without Values", func(t *testing.T) {
+		for _, f := range []string{"simple-v1", "standard-v1"} {
+			b := []byte(`{"format":"` + f + `","tree":["0x00"],"values":[]}`)
+			if _, err := FromOpenZeppelinJSON(sha3.NewLegacyKeccak256(), b); err != ErrOpenZeppelin {
+				t.Errorf("expected ErrOpenZeppelin for %s, got %v", f, err)
+			}
+		}
+	})
+}
+
+func TestStandardTree_ToOpenZeppelinJSON(t *testing.T) {
+	tree, err := NewStandardTree([]string{"address", "uint256"}, ozStandardValues)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	b, err := tree.ToOpenZeppelinJSON()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var exp, act interface{}
+	json.Unmarshal([]byte(ozStandardFixture), &exp)
+	json.Unmarshal(b, &act)
+	if !reflect.DeepEqual(exp, act) {
+		t.Errorf("expected %s, got %s", ozStandardFixture, b)
+	}
+}
+
+func TestStandardLeaf(t *testing.T) {
+	t.Run("Should Encode Static Types", func(t *testing.T) {
+		values := []interface{}{
+			"0x1111111111111111111111111111111111111111", true, "0xabcd", json.Number("7"), "0x10", -1, big.NewInt(3),
+		}
+		types := []string{"address", "bool", "bytes2", "uint8", "uint256", "int8", "int"}
+		act, err := StandardLeaf(types, values)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		encoded, _ := hex.DecodeString("" +
+			"0000000000000000000000001111111111111111111111111111111111111111" +
+			"0000000000000000000000000000000000000000000000000000000000000001" +
+			"abcd000000000000000000000000000000000000000000000000000000000000" +
+			"0000000000000000000000000000000000000000000000000000000000000007" +
+			"0000000000000000000000000000000000000000000000000000000000000010" +
+			"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff" +
+			"0000000000000000000000000000000000000000000000000000000000000003")
+		h := sha3.NewLegacyKeccak256()
+		h.Write(encoded)
+		exp := h.Sum(nil)
+		h.Reset()
+		h.Write(exp)
+		if exp = h.Sum(nil); !bytes.Equal(act, exp) {
+			t.Errorf("expected leaf to be %x, got %x", exp, act)
+		}
+	})
+
+	for name, c := range map[string]struct {
+		typ   string
+		value interface{}
+	}{
+		"Unsupported Type":  {"string", "a"},
+		"Short Address":     {"address", "0x11"},
+		"Overflowing Uint":  {"uint8", "256"},
+		"Negative Uint":     {"uint256", -1},
+		"Underflowing Int":  {"int8", "-129"},
+		"Mismatching Bytes": {"bytes4", "0xabcd"},
+	} {
+		t.Run("With "+name+" Should Return ErrOpenZeppelin", func(t *testing.T) {
+			if _, err := StandardLeaf([]string{c.typ}, []interface{}{c.value}); !errors.Is(err, ErrOpenZeppelin) {
+				t.Errorf("expected ErrOpenZeppelin, got %v", err)
+			}
+		})
+	}
+}
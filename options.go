@@ -0,0 +1,566 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"hash"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Option customises how a Tree is built.
+type Option func(*config)
+
+// CombineFunc hashes the l (left) and r (right) children
+// hashes into their parent hash using the provided hashing algorithm.
+type CombineFunc func(h hash.Hash, l, r []byte) []byte
+
+// SizedCombineFunc behaves like CombineFunc but it also receives
+// the number of leaves committed by the parent being hashed.
+type SizedCombineFunc func(h hash.Hash, l, r []byte, size int) []byte
+
+// config holds the settings the Option(s) are applied to.
+type config struct {
+	// mode is the scheme the tree is constructed with.
+	mode Mode
+	// duplicateOdd pairs odd nodes with themselves rather than promoting them.
+	duplicateOdd bool
+	// combine hashes pairs of children into their parent.
+	combine SizedCombineFunc
+	// sized tells whether combine needs subtree sizes,
+	// so that we only keep track of them when needed.
+	sized bool
+	// less orders leaves as well as children pairs.
+	less func(a, b []byte) bool
+	// leafPrefix is prepended to raw leaves data before hashing.
+	leafPrefix []byte
+	// withoutLeaves discards leaves and inner nodes keeping the root only.
+	withoutLeaves bool
+	// sortedCheck asserts leaves are sorted when trusted to be.
+	sortedCheck bool
+	// oddHandler overrides how odd nodes are carried up, if set.
+	oddHandler OddHandler
+	// hashLock guards the hashing algorithm when shared, if set.
+	hashLock sync.Locker
+	// maxDepth bounds the tree depth, if greater than 0.
+	maxDepth int
+	// indexedLeaves mixes the leaves index into their hash.
+	indexedLeaves bool
+	// padding is the number of leaves to pad up to with
+	// blinding leaves read from rng, if greater than 0.
+	padding int
+	rng     io.Reader
+	// emptyPadding pads the leaves up to a power of two with H("").
+	emptyPadding bool
+	// lengthPrefix prepends the length of every hashed operand.
+	lengthPrefix bool
+	// multiplicities collapses equal leaves annotating their count.
+	multiplicities bool
+	// cache memoizes parents hashes keyed by their children, if set.
+	cache Cache
+	// sizeCommitment binds the root to the number of leaves.
+	sizeCommitment bool
+	// rejectEqualSiblings rejects sorted proofs whose
+	// sibling equals the hash being folded.
+	rejectEqualSiblings bool
+	// bloomRate is the false positive rate of the
+	// leaves Bloom filter, kept if within (0, 1).
+	bloomRate float64
+	// levelHasher picks the hashing algorithm of each level, if set.
+	levelHasher func(level int) hash.Hash
+}
+
+// newConfig makes a config with defaults and applies the provided Option(s).
+func newConfig(opts ...Option) *config {
+	c := &config{
+		combine: func(h hash.Hash, l, r []byte, _ int) []byte {
+			return combine(h, l, r)
+		},
+		less: bytesLess,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithCombine overrides how children pairs are hashed into their parent.
+// By default, the left and right hashes are simply concatenated and hashed.
+//
+// Note that proofs for trees built with a custom CombineFunc can't be
+// verified with Verify as it assumes the default combination.
+func WithCombine(fn CombineFunc) Option {
+	return func(c *config) {
+		c.combine = func(h hash.Hash, l, r []byte, _ int) []byte {
+			return fn(h, l, r)
+		}
+		c.sized = false
+	}
+}
+
+// WithLengthPrefix makes every operand being hashed be prefixed with its
+// length as an unsigned varint, both children pairs and the leaf prefix
+// and data hashed by NewTreeFromData, so that concatenations can't be
+// ambiguous once mixing prefixes or variable length data.
+// It overrides how pairs are hashed same as WithCombine does, thus it
+// has to come after WithMode, and proofs can be verified by providing
+// the same Option to VerifyWith or VerifyData.
+func WithLengthPrefix() Option {
+	return func(c *config) {
+		c.lengthPrefix = true
+		c.combine = func(h hash.Hash, l, r []byte, _ int) []byte {
+			h.Reset()
+			writeLengthPrefixed(h, l)
+			writeLengthPrefixed(h, r)
+			return h.Sum(nil)
+		}
+		c.sized = false
+	}
+}
+
+// writeLengthPrefixed writes b prefixed with its length as an unsigned varint.
+func writeLengthPrefixed(h hash.Hash, b []byte) {
+	var l [binary.MaxVarintLen64]byte
+	h.Write(l[:binary.PutUvarint(l[:], uint64(len(b)))])
+	h.Write(b)
+}
+
+// Cache memoizes parents hashes keyed by the concatenation of their
+// children hashes, see WithCache. Implementations must be safe for
+// concurrent use if shared across trees being built concurrently.
+type Cache interface {
+	Get(key []byte) ([]byte, bool)
+	Put(key, val []byte)
+}
+
+// WithCache memoizes parents hashes in the provided Cache, so that building
+// trees over largely overlapping leaves skips hashing the parents whose
+// children are unchanged. There's no cache by default.
+//
+// Keys are the left and right children hashes concatenated, followed by
+// the parent size as a big endian uint64 when combining with sizes.
+// Being the hashing algorithm and the way pairs are hashed not part of
+// the key, a Cache must only be shared by trees built the same way.
+func WithCache(cache Cache) Option {
+	return func(c *config) {
+		c.cache = cache
+	}
+}
+
+// cachedCombine combines l and r same as combine does, looking the
+// parent hash up in the config cache first, if any, and storing it after.
+func (c *config) cachedCombine(h hash.Hash, l, r []byte, size int) []byte {
+	if c.cache == nil {
+		return c.combine(h, l, r, size)
+	}
+	key := make([]byte, 0, len(l)+len(r)+8)
+	key = append(append(key, l...), r...)
+	if c.sized {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(size))
+		key = append(key, b[:]...)
+	}
+	if p, ok := c.cache.Get(key); ok {
+		return p
+	}
+	p := c.combine(h, l, r, size)
+	c.cache.Put(key, p)
+	return p
+}
+
+// WithSizedCombine overrides how children pairs are hashed into their parent
+// providing fn with the number of leaves under the parent as well.
+// This is useful to build tree heads for authenticated logs
+// which commit to the subtree size, for example :
+//
+//	merkle.WithSizedCombine(func(h hash.Hash, l, r []byte, size int) []byte {
+//	    h.Reset()
+//	    binary.Write(h, binary.BigEndian, uint64(size))
+//	    h.Write(l)
+//	    h.Write(r)
+//	    return h.Sum(nil)
+//	})
+//
+// Keeping track of subtree sizes has a small cost, which is only paid
+// by trees built with this Option.
+func WithSizedCombine(fn SizedCombineFunc) Option {
+	return func(c *config) {
+		c.combine = fn
+		c.sized = true
+	}
+}
+
+// OddHandler returns the Node to carry up in place of the odd Node found
+// at the end of the provided level, starting from 0 at the leaves.
+type OddHandler func(odd *Node, level int) *Node
+
+// WithOddHandler overrides how odd nodes are handled level by level, for
+// matching external trees that neither always promote nor always duplicate
+// them. Returning the odd Node itself promotes it, while any other Node is
+// made the odd's parent, e.g. duplicating odd nodes at even levels only :
+//
+//	merkle.WithOddHandler(func(odd *merkle.Node, level int) *merkle.Node {
+//	    if level%2 == 1 {
+//	        return odd
+//	    }
+//	    h := sha256.New()
+//	    h.Write(odd.Bytes())
+//	    h.Write(odd.Bytes())
+//	    return merkle.NodesFromBytes(h.Sum(nil))[0]
+//	})
+//
+// It takes precedence over the odd handling of the tree Mode. Being Nodes
+// required, LazyTree ignores it and trees built WithoutLeaves build their
+// inner nodes anyway. Proofs include the odd Node as its own sibling
+// wherever it's not promoted, thus the verifier has to mirror the handler.
+func WithOddHandler(fn OddHandler) Option {
+	return func(c *config) {
+		c.oddHandler = fn
+	}
+}
+
+// WithLevelHasher makes the tree hash the children at each level with the
+// hashing algorithm fn returns for such level, 0 being the leaves level,
+// rather than with the tree one, e.g. a fast hash for the wide lower levels
+// and a stronger one near the root :
+//
+//	merkle.WithLevelHasher(func(level int) hash.Hash {
+//	    if level < 8 {
+//	        return fastHashers[level]
+//	    }
+//	    return strongHashers[level]
+//	})
+//
+// fn is called for each level the tree is built or verified at, hence it
+// should hand out a hash.Hash per level rather than a new one each call,
+// and none of them should be shared with trees used concurrently. Hashers
+// are expected to share the output size, as proofs are checked against the
+// tree HashSize. Leaves are still hashed with the tree hashing algorithm.
+//
+// Verification requires knowing the level mapping as well as the level of
+// each proof step, which is told by the leaf index and the tree size, see
+// VerifyLevelHashed. Update rebuilds the whole tree of such trees.
+func WithLevelHasher(fn func(level int) hash.Hash) Option {
+	return func(c *config) {
+		c.levelHasher = fn
+	}
+}
+
+// WithHashLock makes the tree hold mu whenever it uses its hashing
+// algorithm, that is, while being built, on Insert and on Verify.
+// This allows trees sharing the same hash.Hash, and mu, to be built and
+// used from multiple goroutines, at the cost of serialising their hashing.
+// Giving each tree its own hash.Hash, e.g. with NewTreeFunc, is preferable.
+func WithHashLock(mu sync.Locker) Option {
+	return func(c *config) {
+		c.hashLock = mu
+	}
+}
+
+// lock acquires the config hashLock, if any,
+// returning the func releasing it, e.g. :
+//
+//	defer c.lock()()
+func (c *config) lock() func() {
+	if c.hashLock == nil {
+		return func() {}
+	}
+	c.hashLock.Lock()
+	return c.hashLock.Unlock
+}
+
+// WithMaxDepth bounds the depth of the tree, that is, the number of levels
+// above the leaves, which is checked from the number of leaves before
+// building anything. It's a defensive limit for services accepting
+// untrusted numbers of leaves, NewTreeE returns ErrMaxDepth whenever
+// the tree would exceed it while the other constructors panic.
+// Insert returns ErrMaxDepth as well rather than growing the tree past it.
+func WithMaxDepth(d int) Option {
+	return func(c *config) {
+		c.maxDepth = d
+	}
+}
+
+// exceedsDepth tells whether a tree of n leaves would exceed the config
+// maxDepth once padded, the leaves of trees being padded before building.
+func (c *config) exceedsDepth(n int) bool {
+	return c.maxDepth > 0 && height(c.padded(n)) > c.maxDepth
+}
+
+// leafCount returns the number of leaves the provided hashed leaves make
+// before padding, that is, the number of distinct ones WithMultiplicities.
+func (c *config) leafCount(hl [][]byte) int {
+	if !c.multiplicities {
+		return len(hl)
+	}
+	distinct := make(map[string]bool, len(hl))
+	for _, l := range hl {
+		distinct[string(l)] = true
+	}
+	return len(distinct)
+}
+
+// WithSortedCheck makes NewTreeSorted assert that the provided leaves are
+// actually sorted, panicking otherwise. It's meant to be used while
+// debugging as it costs an additional pass over the leaves.
+func WithSortedCheck() Option {
+	return func(c *config) {
+		c.sortedCheck = true
+	}
+}
+
+// WithLeafPrefix sets the prefix prepended to raw leaves data before they
+// get hashed by NewTreeFromData, which is a common way to domain separate
+// leaves from inner nodes, e.g. RFC 6962 uses a 0x00 prefix.
+// By default no prefix is applied.
+func WithLeafPrefix(prefix []byte) Option {
+	return func(c *config) {
+		c.leafPrefix = prefix
+	}
+}
+
+// WithIndexedLeaves makes NewTreeFromData mix the index of each leaf into
+// its hash, that is, the leaf hash is H(prefix || index || data) where
+// index is a big endian uint64. This binds every proof to a specific
+// position, preventing a proof from being replayed for the same data
+// at a different position. It's meant for ModeOrdered trees, whose
+// proofs can be verified with VerifyIndexed.
+func WithIndexedLeaves() Option {
+	return func(c *config) {
+		c.indexedLeaves = true
+	}
+}
+
+// WithBlindingPadding pads the leaves up to targetSize with blinding leaves
+// read from rng, so that the root doesn't leak the number of actual leaves.
+// targetSize is rounded up to a power of two, as well as the number of
+// leaves whenever it's greater than targetSize. If rng is nil, blinding
+// leaves are read from crypto/rand. Proofs of actual leaves verify as usual.
+//
+// Padding is applied by NewTree and NewTreeFromData, which panic if
+// reading from rng fails.
+func WithBlindingPadding(targetSize int, rng io.Reader) Option {
+	return func(c *config) {
+		c.padding = targetSize
+		c.rng = rng
+		if rng == nil {
+			c.rng = rand.Reader
+		}
+	}
+}
+
+// WithEmptyHashPadding pads the leaves up to the next power of two with the
+// hash of the empty string, that is, H(""), e.g. e3b0c442...b855 for sha256,
+// making a balanced tree whose proofs all have the same length.
+// Being the filler well known, external verifiers can reproduce the root.
+// Padding leaves are appended to the provided ones, in modes sorting leaves
+// they are sorted along with them. Proofs of actual leaves verify as usual.
+//
+// Padding is applied by NewTree and NewTreeFromData.
+func WithEmptyHashPadding() Option {
+	return func(c *config) {
+		c.emptyPadding = true
+	}
+}
+
+// pad appends padding leaves, as big as the h output, to the provided
+// leaves up to the config padding rounded up to a power of two, either
+// blinding ones or the empty string hash, see WithEmptyHashPadding.
+func (c *config) pad(h hash.Hash, leaves Nodes) Nodes {
+	target := c.padded(len(leaves))
+	if target == len(leaves) {
+		return leaves
+	}
+	var empty []byte
+	if c.emptyPadding {
+		unlock := c.lock()
+		h.Reset()
+		empty = h.Sum(nil)
+		unlock()
+	}
+	for len(leaves) < target {
+		b := empty
+		if b == nil {
+			b = make([]byte, h.Size())
+			if _, err := io.ReadFull(c.rng, b); err != nil {
+				panic(err)
+			}
+		}
+		leaves = append(leaves, newNode(b))
+	}
+	return leaves
+}
+
+// padded returns the number of leaves n leaves are padded up to,
+// which is n itself unless padding either WithBlindingPadding
+// or WithEmptyHashPadding.
+func (c *config) padded(n int) int {
+	if !c.pads() {
+		return n
+	}
+	target := 1
+	for target < c.padding || target < n {
+		target <<= 1
+	}
+	return target
+}
+
+// pads tells whether leaves are padded, either WithBlindingPadding
+// or WithEmptyHashPadding.
+func (c *config) pads() bool {
+	return c.padding > 0 || c.emptyPadding
+}
+
+// WithRejectEqualSiblings makes verifiers of sorted proofs, e.g. VerifyWith,
+// reject proofs having a sibling equal to the hash folded so far.
+//
+// Folding such a step is not ambiguous, as an equal pair concatenates the
+// same whichever way round, hence it's accepted by default. Yet, an equal
+// sibling can only come from two equal subtrees, that is, from duplicate
+// leaves, which trees over sets of distinct leaves never have, while it's
+// what a forger reusing the accumulator as a fake sibling would present.
+// It's meant for such trees, as it rejects proofs of duplicate leaves.
+func WithRejectEqualSiblings() Option {
+	return func(c *config) {
+		c.rejectEqualSiblings = true
+	}
+}
+
+// WithoutLeaves makes the tree compute its merkle root without storing
+// either leaves or inner nodes, which are discarded as soon as the root is
+// computed. This is a memory optimisation for callers needing the merkle
+// root only, or keeping leaves elsewhere to build proofs on their own.
+//
+// As a trade-off, trees built with this Option can't build proofs, that
+// is, Proof returns an empty proof and Prove returns ErrNoLeaves.
+// Having no leaves, the root Node has no children either.
+func WithoutLeaves() Option {
+	return func(c *config) {
+		c.withoutLeaves = true
+	}
+}
+
+// WithLess overrides how leaves are sorted, by default they're sorted
+// lexicographically which is fine for hashes but a different ordering may
+// be needed for other kinds of leaves, e.g. numeric ids.
+// Children pairs are ordered with less as well before being hashed.
+//
+// Proofs for trees built with a custom less can be verified by
+// providing the same Option to VerifyWith.
+func WithLess(less func(a, b []byte) bool) Option {
+	return func(c *config) {
+		c.less = less
+	}
+}
+
+// order returns the provided pair of hashes ordered with the config less.
+// Equal hashes are returned as provided, which is deterministic as their
+// concatenation is the same whichever way round, see WithRejectEqualSiblings.
+func (c *config) order(l, r []byte) ([]byte, []byte) {
+	if c.less(r, l) {
+		return r, l
+	}
+	return l, r
+}
+
+// sortNodes sorts the provided Nodes with the config less.
+func (c *config) sortNodes(ns Nodes) {
+	sort.Slice(ns, func(i, j int) bool {
+		return c.less(ns[i].val, ns[j].val)
+	})
+}
+
+// search finds the index of hl within n sorted leaves, where leaf returns
+// the leaf at the provided index, reporting whether it was found.
+// Leaves that are equal according to the config less are scanned
+// until the one with the very same bytes is found.
+func (c *config) search(n int, leaf func(i int) []byte, hl []byte) (int, bool) {
+	i := sort.Search(n, func(i int) bool {
+		return !c.less(leaf(i), hl) // leaf(i) >= hl
+	})
+	for j := i; j < n && !c.less(hl, leaf(j)); j++ {
+		if bytes.Equal(leaf(j), hl) {
+			return j, true
+		}
+	}
+	return i, false
+}
+
+// reconstruct folds the proof over leaf and returns the implied merkle root.
+// It returns nil if rejecting a sibling equal to the hash being folded.
+func (c *config) reconstruct(h hash.Hash, leaf []byte, proof [][]byte) []byte {
+	for _, p := range proof {
+		if c.rejectEqualSiblings && bytes.Equal(leaf, p) {
+			return nil
+		}
+		l, r := c.order(leaf, p)
+		leaf = c.combine(h, l, r, 0)
+	}
+	return leaf
+}
+
+// reconstructAt folds the proof over the leaf at index within size leaves
+// and returns the implied merkle root, for positional modes the index tells
+// left siblings from right ones at each level. It reports whether the
+// proof has exactly the number of steps expected for the leaf position.
+func (c *config) reconstructAt(h hash.Hash, leaf []byte, proof [][]byte, index, size int) ([]byte, bool) {
+	if !c.mode.positional() && c.levelHasher == nil {
+		return c.reconstruct(h, leaf, proof), true
+	}
+	if index < 0 || index >= size {
+		return nil, false
+	}
+	k := 0
+	for n, level := size, 0; n > 1; n, level = (n+1)/2, level+1 {
+		// a node at the end of an odd level is either promoted
+		// without any step or it's paired with itself.
+		if index%2 == 1 || index+1 < n || c.duplicateOdd {
+			if k >= len(proof) {
+				return nil, false
+			}
+			l, r := leaf, proof[k]
+			if !c.mode.positional() {
+				// the level of each step is all the index is needed for.
+				l, r = c.order(l, r)
+			} else if index%2 == 1 {
+				// the sibling is a left child node
+				l, r = r, l
+			}
+			leaf = c.combine(c.hasher(h, level), l, r, 0)
+			k++
+		}
+		index /= 2
+	}
+	return leaf, k == len(proof)
+}
+
+// hasher returns the hashing algorithm of the provided
+// level, which is h unless set WithLevelHasher.
+func (c *config) hasher(h hash.Hash, level int) hash.Hash {
+	if c.levelHasher == nil {
+		return h
+	}
+	return c.levelHasher(level)
+}
+
+// verifyAt verifies whether the proof for the leaf at index
+// within size leaves is valid against root, see reconstructAt.
+func (c *config) verifyAt(h hash.Hash, leaf, root []byte, proof [][]byte, index, size int) bool {
+	implied, ok := c.reconstructAt(h, leaf, proof, index, size)
+	return ok && bytes.Equal(implied, root)
+}
+
+// bytesLess is the default less, it orders lexicographically.
+func bytesLess(a, b []byte) bool {
+	return bytes.Compare(a, b) == -1
+}
+
+// combine is the default CombineFunc, it hashes l and r concatenated.
+func combine(h hash.Hash, l, r []byte) []byte {
+	h.Reset()
+	h.Write(l)
+	h.Write(r)
+	return h.Sum(nil)
+}
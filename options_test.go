@@ -0,0 +1,640 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"hash"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestWithCombine(t *testing.T) {
+	prefixed := func(h hash.Hash, l, r []byte) []byte {
+		h.Reset()
+		h.Write([]byte{1})
+		h.Write(l)
+		h.Write(r)
+		return h.Sum(nil)
+	}
+
+	leaves := hashStringSlice(algo, "a", "b")
+	sort.Slice(leaves, func(i, j int) bool { return bytes.Compare(leaves[i], leaves[j]) == -1 })
+
+	t.Run("Should Use Provided Combine Func", func(t *testing.T) {
+		tree := NewTree(algo, leaves, WithCombine(prefixed))
+		if exp := prefixed(algo, leaves[0], leaves[1]); !bytes.Equal(tree.Root().Bytes(), exp) {
+			t.Errorf("expected merkle root to be %x, got %s", exp, tree.Root())
+		}
+	})
+
+	t.Run("Should Default To Plain Concatenation", func(t *testing.T) {
+		tree := NewTree(algo, leaves)
+		if exp := combine(algo, leaves[0], leaves[1]); !bytes.Equal(tree.Root().Bytes(), exp) {
+			t.Errorf("expected merkle root to be %x, got %s", exp, tree.Root())
+		}
+	})
+}
+
+func TestWithSizedCombine(t *testing.T) {
+	sized := func(h hash.Hash, l, r []byte, size int) []byte {
+		h.Reset()
+		binary.Write(h, binary.BigEndian, uint64(size)) // nolint:errcheck
+		h.Write(l)
+		h.Write(r)
+		return h.Sum(nil)
+	}
+
+	leaves := hashStringSlice(algo, "a", "b", "c")
+	sort.Slice(leaves, func(i, j int) bool { return bytes.Compare(leaves[i], leaves[j]) == -1 })
+
+	t.Run("Should Provide Subtree Sizes", func(t *testing.T) {
+		var sizes []int
+		NewTree(algo, leaves, WithSizedCombine(func(h hash.Hash, l, r []byte, size int) []byte {
+			sizes = append(sizes, size)
+			return sized(h, l, r, size)
+		}))
+		if len(sizes) != 2 || sizes[0] != 2 || sizes[1] != 3 {
+			t.Errorf("expected subtree sizes to be [2 3], got %v", sizes)
+		}
+	})
+
+	t.Run("Should Return Expected Merkle Root", func(t *testing.T) {
+		tree := NewTree(algo, leaves, WithSizedCombine(sized))
+		// leaves 0 and 1 are paired, leaf 2 is promoted.
+		p := sized(algo, leaves[0], leaves[1], 2)
+		l, r := p, leaves[2]
+		if bytes.Compare(l, r) == 1 {
+			l, r = r, l
+		}
+		if exp := sized(algo, l, r, 3); !bytes.Equal(tree.Root().Bytes(), exp) {
+			t.Errorf("expected merkle root to be %x, got %s", exp, tree.Root())
+		}
+	})
+}
+
+func TestWithLess(t *testing.T) {
+	// orders decimal numbers by their numeric value.
+	numeric := func(a, b []byte) bool {
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return bytes.Compare(a, b) == -1
+	}
+
+	leaves := [][]byte{[]byte("100"), []byte("9"), []byte("20"), []byte("3"), []byte("1000")}
+	tree := NewTree(algo, leaves, WithLess(numeric))
+
+	t.Run("Should Sort Leaves With Provided Less", func(t *testing.T) {
+		exp := []string{"3", "9", "20", "100", "1000"}
+		for i, l := range tree.leaves {
+			if string(l.val) != exp[i] {
+				t.Errorf("expected leaf at index %d to be %s, got %s", i, exp[i], l.val)
+			}
+		}
+	})
+
+	t.Run("Should Find Leaves And Build Verifiable Proofs", func(t *testing.T) {
+		for _, l := range leaves {
+			proof, _, ok := tree.ProofWithIndex(l)
+			if !ok {
+				t.Fatalf("expected leaf %s to be found", l)
+			}
+			if !VerifyWith(algo, l, tree.Root().Bytes(), proof.ToByteArrays(), WithLess(numeric)) {
+				t.Errorf("proof for leaf %s should have been valid", l)
+			}
+		}
+	})
+
+	t.Run("Should Not Find Non Existent Leaf", func(t *testing.T) {
+		if _, _, ok := tree.ProofWithIndex([]byte("10")); ok {
+			t.Errorf("expected leaf not to be found")
+		}
+	})
+}
+
+func TestWithoutLeaves(t *testing.T) {
+	tree := NewTree(algo, hashStringSlice(algo, "a", "b", "c", "d", "e"), WithoutLeaves())
+
+	t.Run("Should Return Expected Merkle Root", func(t *testing.T) {
+		if exp, act := oddLeavesTree.Root().String(), tree.Root().String(); act != exp {
+			t.Errorf("expected merkle root should have been %s, got %s", exp, act)
+		}
+	})
+
+	t.Run("Should Not Store Leaves Nor Inner Nodes", func(t *testing.T) {
+		if len(tree.leaves) > 0 || !tree.Root().IsLeaf() {
+			t.Errorf("expected no leaves nor inner nodes to be stored")
+		}
+	})
+
+	t.Run("Should Not Build Proofs", func(t *testing.T) {
+		leaf := oddLeavesTree.leaves[0].val
+		if proof := tree.Proof(leaf); len(proof) > 0 {
+			t.Errorf("expected empty proof")
+		}
+		if _, err := tree.Prove(leaf); err != ErrNoLeaves {
+			t.Errorf("expected ErrNoLeaves, got %v", err)
+		}
+	})
+}
+
+func TestWithOddHandler(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k")
+
+	t.Run("Should Reproduce Promotion When Returning Odd", func(t *testing.T) {
+		levels := []int{}
+		tree := NewTree(algo, hl, WithOddHandler(func(odd *Node, level int) *Node {
+			levels = append(levels, level)
+			return odd
+		}))
+		if exp, act := NewTree(algo, hl).Root().String(), tree.Root().String(); act != exp {
+			t.Errorf("expected merkle root should have been %s, got %s", exp, act)
+		}
+		// 11 leaves, 6 nodes at level 1, 3 at level 2 and 2 at level 3.
+		if len(levels) != 2 || levels[0] != 0 || levels[1] != 2 {
+			t.Errorf("expected handler to be called at levels [0 2], got %v", levels)
+		}
+	})
+
+	t.Run("Should Reproduce Duplication When Returning Parent", func(t *testing.T) {
+		duplicate := WithOddHandler(func(odd *Node, _ int) *Node {
+			return NodesFromBytes(rehash(algo, combine(algo, odd.Bytes(), odd.Bytes())))[0]
+		})
+		tree := NewTree(algo, hl, WithMode(ModeBitcoin), duplicate)
+		root := tree.Root().Bytes()
+		if exp, act := NewTree(algo, hl, WithMode(ModeBitcoin)).Root().String(), tree.Root().String(); act != exp {
+			t.Errorf("expected merkle root should have been %s, got %s", exp, act)
+		}
+		for i, l := range tree.leaves {
+			if !VerifyMode(algo, ModeBitcoin, l.val, root, tree.Proof(l.val).ToByteArrays(), i, len(hl)) {
+				t.Errorf("proof for leaf %d should have been valid", i)
+			}
+		}
+	})
+
+	t.Run("Should Apply To Trees Built Without Leaves", func(t *testing.T) {
+		half := WithOddHandler(func(odd *Node, level int) *Node {
+			if level%2 == 1 {
+				return odd
+			}
+			return NodesFromBytes(combine(algo, odd.Bytes(), odd.Bytes()))[0]
+		})
+		exp := NewTree(algo, hl, half).Root().String()
+		if act := NewTree(algo, hl, half, WithoutLeaves()).Root().String(); act != exp {
+			t.Errorf("expected merkle root should have been %s, got %s", exp, act)
+		}
+		if exp == NewTree(algo, hl).Root().String() {
+			t.Errorf("expected merkle root to differ from promoting odd nodes")
+		}
+	})
+}
+
+// TestWithHashLock is meant to be run with -race.
+func TestWithHashLock(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+	exp := oddLeavesTree.Root().String()
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+	expMultiset := NewTree(sha256.New(), hl, WithMultiplicities()).Root().String()
+	// a single hashing algorithm shared by every tree.
+	h := sha256.New()
+	var mu sync.Mutex
+
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tree := NewTree(h, hl, WithHashLock(&mu))
+			if act := tree.Root().String(); act != exp {
+				t.Errorf("expected merkle root should have been %s, got %s", exp, act)
+			}
+			if ok, _ := tree.Verify(hl[0], tree.Proof(hl[0])); !ok {
+				t.Errorf("proof should have been valid")
+			}
+			// leaves hashed while building hold the lock too.
+			if act := NewTreeFromData(h, data, WithHashLock(&mu)).Root().String(); act != exp {
+				t.Errorf("expected merkle root from data should have been %s, got %s", exp, act)
+			}
+			if act := NewTree(h, hl, WithHashLock(&mu), WithMultiplicities()).Root().String(); act != expMultiset {
+				t.Errorf("expected multiset merkle root should have been %s, got %s", expMultiset, act)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWithMaxDepth(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+
+	t.Run("Should Build Tree Within Depth", func(t *testing.T) {
+		tree, err := NewTreeE(algo, hl, WithMaxDepth(3))
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if exp, act := oddLeavesTree.Root().String(), tree.Root().String(); act != exp {
+			t.Errorf("expected merkle root should have been %s, got %s", exp, act)
+		}
+	})
+
+	t.Run("Should Return ErrMaxDepth", func(t *testing.T) {
+		if _, err := NewTreeE(algo, hl, WithMaxDepth(2)); err != ErrMaxDepth {
+			t.Errorf("expected ErrMaxDepth, got %v", err)
+		}
+		// padding makes a deeper tree than the leaves alone.
+		if _, err := NewTreeE(algo, hl, WithMaxDepth(3), WithBlindingPadding(64, nil)); err != ErrMaxDepth {
+			t.Errorf("expected ErrMaxDepth once padded, got %v", err)
+		}
+	})
+
+	t.Run("Should Count Collapsed Leaves", func(t *testing.T) {
+		// 9 leaves collapse into 8 distinct ones, that is, 3 levels.
+		dup := append(hashStringSlice(algo, "a", "b", "c", "d", "e", "f", "g", "h"), hl[0])
+		if _, err := NewTreeE(algo, dup, WithMaxDepth(3), WithMultiplicities()); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+
+	t.Run("Should Panic Exceeding Depth", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r != ErrMaxDepth {
+				t.Errorf("expected panic with ErrMaxDepth, got %v", r)
+			}
+		}()
+		NewTree(algo, hl, WithMaxDepth(2))
+	})
+
+	t.Run("Should Return ErrMaxDepth On Insert", func(t *testing.T) {
+		tree := NewTree(algo, hl[:3], WithMaxDepth(2))
+		if err := tree.Insert(hl[3]); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+		if err := tree.Insert(hl[4]); err != ErrMaxDepth {
+			t.Errorf("expected ErrMaxDepth, got %v", err)
+		}
+		if len(tree.leaves) != 4 {
+			t.Errorf("expected 4 leaves, got %d", len(tree.leaves))
+		}
+	})
+
+	t.Run("Should Return ErrMaxDepth On Buffered Insert", func(t *testing.T) {
+		tree := NewBufferedTree(algo, hl[:2], 4, WithMaxDepth(2))
+		for _, l := range hl[2:4] {
+			if err := tree.Insert(l); err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+		}
+		if err := tree.Insert(hl[4]); err != ErrMaxDepth {
+			t.Errorf("expected ErrMaxDepth, got %v", err)
+		}
+		if tree.Flush(); len(tree.leaves) != 4 {
+			t.Errorf("expected 4 leaves, got %d", len(tree.leaves))
+		}
+	})
+}
+
+func TestWithBlindingPadding(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+
+	t.Run("Should Pad Up To Target Size", func(t *testing.T) {
+		tree := NewTree(algo, hl, WithBlindingPadding(6, nil))
+		if len(tree.leaves) != 8 {
+			t.Errorf("expected 8 leaves, got %d", len(tree.leaves))
+		}
+		for _, l := range hl {
+			if !Verify(algo, l, tree.Root().Bytes(), tree.Proof(l).ToByteArrays()) {
+				t.Errorf("proof for %x should have been valid", l)
+			}
+		}
+	})
+
+	t.Run("Should Blind Root", func(t *testing.T) {
+		a := NewTree(algo, hl, WithBlindingPadding(8, nil)).Root().String()
+		b := NewTree(algo, hl, WithBlindingPadding(8, nil)).Root().String()
+		if a == b || a == oddLeavesTree.Root().String() {
+			t.Errorf("expected blinded merkle roots to differ")
+		}
+	})
+
+	t.Run("Should Be Deterministic With Caller Supplied Rng", func(t *testing.T) {
+		rng := func() io.Reader { return bytes.NewReader(bytes.Repeat([]byte{7}, 3*algo.Size())) }
+		a := NewTreeFromData(algo, [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}, WithBlindingPadding(8, rng()))
+		b := NewTree(algo, hl, WithBlindingPadding(8, rng()))
+		if a.Root().String() != b.Root().String() {
+			t.Errorf("expected merkle roots to be the same")
+		}
+	})
+
+	t.Run("Should Round Up Leaves Exceeding Target Size", func(t *testing.T) {
+		if tree := NewTree(algo, hl, WithBlindingPadding(2, nil)); len(tree.leaves) != 8 {
+			t.Errorf("expected 8 leaves, got %d", len(tree.leaves))
+		}
+	})
+
+	t.Run("Should Blind Root Again On Mutation", func(t *testing.T) {
+		tree := NewTree(algo, hl[:3], WithBlindingPadding(8, nil))
+		blinding := func() map[string]bool {
+			m := make(map[string]bool, len(tree.padding))
+			for l := range tree.padding {
+				m[l.Hex()] = true
+			}
+			return m
+		}
+		for _, m := range []struct {
+			name   string
+			mutate func() error
+		}{
+			{"Insert", func() error { return tree.Insert(hl[3]) }},
+			{"Update", func() error { return tree.Update(hl[3], hl[4]) }},
+		} {
+			name, before := m.name, blinding()
+			if err := m.mutate(); err != nil {
+				t.Fatalf("%s: unexpected error %v", name, err)
+			}
+			if len(tree.leaves) != 8 || len(tree.padding) != 4 {
+				t.Errorf("%s: expected 8 leaves, 4 of which blinding, got %d and %d", name, len(tree.leaves), len(tree.padding))
+			}
+			for l := range blinding() {
+				if before[l] {
+					t.Errorf("%s: expected blinding leaf %s to be regenerated", name, l)
+				}
+			}
+		}
+		for _, l := range [][]byte{hl[0], hl[1], hl[2], hl[4]} {
+			if !Verify(algo, l, tree.Root().Bytes(), tree.Proof(l).ToByteArrays()) {
+				t.Errorf("proof for %x should have been valid", l)
+			}
+		}
+		if err := tree.Update(hl[3], hl[0]); err != ErrLeafNotFound {
+			t.Errorf("expected ErrLeafNotFound, got %v", err)
+		}
+	})
+}
+
+func TestWithEmptyHashPadding(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+	empty := hashStringSlice(algo, "")[0]
+
+	for _, m := range []Mode{ModeSorted, ModeOrdered, ModeRFC6962} {
+		t.Run("Should Pad To Next Power Of Two In "+m.String()+" Mode", func(t *testing.T) {
+			tree := NewTree(algo, hl, WithMode(m), WithEmptyHashPadding())
+			exp := NewTree(algo, append(hl, empty, empty, empty), WithMode(m))
+			if len(tree.leaves) != 8 {
+				t.Errorf("expected 8 leaves, got %d", len(tree.leaves))
+			}
+			if tree.Root().String() != exp.Root().String() {
+				t.Errorf("expected merkle root to be %s, got %s", exp.Root(), tree.Root())
+			}
+			for _, l := range hl {
+				proof, i, _ := tree.ProofWithIndex(l)
+				if proof.Len() != 3 || !VerifyMode(algo, m, l, tree.Root().Bytes(), proof.ToByteArrays(), i, 8) {
+					t.Errorf("proof for %x should have been valid with 3 steps", l)
+				}
+			}
+		})
+	}
+
+	t.Run("Should Not Pad A Power Of Two", func(t *testing.T) {
+		if tree := NewTree(algo, hl[:4], WithEmptyHashPadding()); len(tree.leaves) != 4 {
+			t.Errorf("expected 4 leaves, got %d", len(tree.leaves))
+		}
+	})
+
+	for _, m := range []Mode{ModeSorted, ModeOrdered, ModeRFC6962} {
+		t.Run("Should Pad Inserted Leaves In "+m.String()+" Mode", func(t *testing.T) {
+			tree := NewTree(algo, hl[:4], WithMode(m), WithEmptyHashPadding())
+			if err := tree.Insert(hl[4]); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			exp := NewTree(algo, hl, WithMode(m), WithEmptyHashPadding())
+			if len(tree.leaves) != 8 {
+				t.Errorf("expected 8 leaves, got %d", len(tree.leaves))
+			}
+			if tree.Root().String() != exp.Root().String() {
+				t.Errorf("expected merkle root to be %s, got %s", exp.Root(), tree.Root())
+			}
+		})
+	}
+}
+
+func TestWithLengthPrefix(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e")}
+	tree := NewTreeFromData(algo, data, WithLeafPrefix([]byte{0}), WithLengthPrefix())
+	root := tree.Root().Bytes()
+
+	t.Run("Should Prefix Operands Length", func(t *testing.T) {
+		exp := hashStringSlice(algo, "\x01\x00\x01c")[0]
+		if _, _, ok := tree.ProofWithIndex(exp); !ok {
+			t.Errorf("expected length prefixed leaf to be found")
+		}
+		l, r := tree.leaves[0].val, tree.leaves[1].val
+		exp = hashStringSlice(algo, "\x20"+string(l)+"\x20"+string(r))[0]
+		if !bytes.Equal(tree.leaves[0].parent.val, exp) {
+			t.Errorf("expected parent to be %x, got %s", exp, tree.leaves[0].parent)
+		}
+	})
+
+	t.Run("Should Be Verified With Same Option", func(t *testing.T) {
+		for _, d := range data {
+			proof := tree.Proof(tree.c.hashLeaf(algo, d)).ToByteArrays()
+			if !VerifyData(algo, d, root, proof, WithLeafPrefix([]byte{0}), WithLengthPrefix()) {
+				t.Errorf("proof for %s should have been valid", d)
+			}
+			if VerifyData(algo, d, root, proof, WithLeafPrefix([]byte{0})) {
+				t.Errorf("proof for %s should have been invalid without length prefix", d)
+			}
+		}
+	})
+}
+
+// mapCache is a Cache counting its hits.
+type mapCache struct {
+	m    map[string][]byte
+	hits int
+}
+
+func (c *mapCache) Get(key []byte) ([]byte, bool) {
+	v, ok := c.m[string(key)]
+	if ok {
+		c.hits++
+	}
+	return v, ok
+}
+
+func (c *mapCache) Put(key, val []byte) {
+	c.m[string(key)] = val
+}
+
+func TestWithCache(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e", "f", "g", "h", "i")
+
+	for _, m := range []Mode{ModeSorted, ModeBitcoin, ModeRFC6962} {
+		t.Run("Should Reuse Unchanged Subtrees In "+m.String()+" Mode", func(t *testing.T) {
+			cache := &mapCache{m: map[string][]byte{}}
+			NewTree(algo, hl[:8], WithMode(m), WithCache(cache))
+			if cache.hits != 0 {
+				t.Fatalf("expected no hits on first build, got %d", cache.hits)
+			}
+			tree := NewTree(algo, hl, WithMode(m), WithCache(cache))
+			if cache.hits == 0 {
+				t.Errorf("expected hits on overlapping build")
+			}
+			if exp := NewTree(algo, hl, WithMode(m)); tree.Root().String() != exp.Root().String() {
+				t.Errorf("expected merkle root to be %s, got %s", exp.Root(), tree.Root())
+			}
+			verifyAllModeProofs(t, tree)
+		})
+	}
+
+	t.Run("Should Key By Size When Combining With Sizes", func(t *testing.T) {
+		sized := WithSizedCombine(func(h hash.Hash, l, r []byte, size int) []byte {
+			var b [8]byte
+			binary.BigEndian.PutUint64(b[:], uint64(size))
+			h.Reset()
+			h.Write(b[:])
+			h.Write(l)
+			h.Write(r)
+			return h.Sum(nil)
+		})
+		cache := &mapCache{m: map[string][]byte{}}
+		NewTree(algo, hl[:3], WithMode(ModeOrdered), sized, WithCache(cache))
+		tree := NewTree(algo, hl, WithMode(ModeOrdered), sized, WithCache(cache))
+		exp := NewTree(algo, hl, WithMode(ModeOrdered), sized)
+		if tree.Root().String() != exp.Root().String() {
+			t.Errorf("expected merkle root to be %s, got %s", exp.Root(), tree.Root())
+		}
+	})
+}
+
+func TestWithRejectEqualSiblings(t *testing.T) {
+	// leaves are sorted as : b, b, a, a, both pairs being equal.
+	hl := hashStringSlice(algo, "a", "b", "a", "b")
+	tree := NewTree(algo, hl)
+	proof := tree.Proof(hl[0]).ToByteArrays()
+	if !bytes.Equal(proof[0], hl[0]) {
+		t.Fatalf("expected the first sibling to equal the leaf")
+	}
+
+	t.Run("Should Fold Equal Siblings By Default", func(t *testing.T) {
+		if !VerifyWith(algo, hl[0], tree.Root().Bytes(), proof) {
+			t.Errorf("proof with an equal sibling should have been valid")
+		}
+	})
+
+	t.Run("Should Reject Equal Siblings", func(t *testing.T) {
+		if VerifyWith(algo, hl[0], tree.Root().Bytes(), proof, WithRejectEqualSiblings()) {
+			t.Errorf("proof with an equal sibling should have been invalid")
+		}
+	})
+
+	t.Run("Should Verify Distinct Siblings", func(t *testing.T) {
+		for _, l := range oddLeavesTree.leaves {
+			if !VerifyWith(algo, l.val, oddLeavesTree.Root().Bytes(), oddLeavesTree.Proof(l.val).ToByteArrays(), WithRejectEqualSiblings()) {
+				t.Errorf("proof for leaf %s should have been valid", l)
+			}
+		}
+	})
+}
+
+func TestWithLevelHasher(t *testing.T) {
+	hashers := []hash.Hash{sha256.New(), sha512.New512_256()}
+	levelHasher := func(level int) hash.Hash {
+		if level == 0 {
+			return hashers[0]
+		}
+		return hashers[1]
+	}
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+
+	t.Run("Should Hash Each Level With Its Hasher", func(t *testing.T) {
+		tree := NewTree(algo, hl[:4], WithMode(ModeOrdered), WithLevelHasher(levelHasher))
+		expected := combine(sha512.New512_256(), combine(sha256.New(), hl[0], hl[1]), combine(sha256.New(), hl[2], hl[3]))
+		if !bytes.Equal(tree.Root().Bytes(), expected) {
+			t.Errorf("expected merkle root to be %x, got %s", expected, tree.Root())
+		}
+		lean := NewTree(algo, hl[:4], WithMode(ModeOrdered), WithLevelHasher(levelHasher), WithoutLeaves())
+		if !bytes.Equal(lean.Root().Bytes(), expected) {
+			t.Errorf("expected merkle root without leaves to be %x, got %s", expected, lean.Root())
+		}
+	})
+
+	for _, mode := range []Mode{ModeSorted, ModeBitcoin, ModeRFC6962, ModeOrdered, ModeUnsorted} {
+		t.Run("Should Verify Proofs For Mode "+mode.String(), func(t *testing.T) {
+			tree := NewTree(algo, hl, WithMode(mode), WithLevelHasher(levelHasher))
+			if tree.Root().String() == NewTree(algo, hl, WithMode(mode)).Root().String() {
+				t.Fatalf("expected merkle root to differ from the single hasher one")
+			}
+			root := tree.Root().Bytes()
+			for _, l := range hl {
+				proof, i, ok := tree.ProofWithIndex(l)
+				if !ok {
+					t.Fatalf("expected leaf %x to be found", l)
+				}
+				if !VerifyLevelHashed(levelHasher, l, root, proof.ToByteArrays(), i, len(hl), WithMode(mode)) {
+					t.Errorf("proof for leaf %x should have been valid", l)
+				}
+				if ok, err := tree.Verify(l, proof); !ok || err != nil {
+					t.Errorf("proof for leaf %x should have been valid, got %t, %v", l, ok, err)
+				}
+				if VerifyMode(algo, mode, l, root, proof.ToByteArrays(), i, len(hl)) {
+					t.Errorf("proof for leaf %x should have been invalid without the level hasher", l)
+				}
+			}
+		})
+	}
+
+	t.Run("Should Stream Same Root As Tree", func(t *testing.T) {
+		// alternating hashers tell every level apart.
+		alternate := func(level int) hash.Hash {
+			return hashers[level%2]
+		}
+		for _, mode := range []Mode{ModeSorted, ModeBitcoin, ModeRFC6962, ModeOrdered} {
+			for n := 1; n <= 17; n++ {
+				data := make([]string, n)
+				for i := range data {
+					data[i] = strconv.Itoa(i)
+				}
+				tree := NewTree(algo, hashStringSlice(algo, data...), WithMode(mode), WithLevelHasher(alternate))
+				act, err := RootFromReader(algo, bytes.NewReader(bytes.Join(tree.leaves.ToByteArrays(), nil)), WithMode(mode), WithLevelHasher(alternate))
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				if exp := tree.Root().Bytes(); !bytes.Equal(act, exp) {
+					t.Errorf("expected merkle root of %d leaves in mode %s to be %x, got %x", n, mode, exp, act)
+				}
+			}
+		}
+	})
+
+	t.Run("Should Reject Proofs Without Levels", func(t *testing.T) {
+		tree := NewTree(algo, hl, WithLevelHasher(levelHasher))
+		root := tree.Root().Bytes()
+		proof := tree.Proof(hl[0])
+		if VerifyWith(algo, hl[0], root, proof.ToByteArrays(), WithLevelHasher(levelHasher)) {
+			t.Errorf("proof should have been rejected by VerifyWith")
+		}
+		steps, err := tree.ProofSteps(hl[0])
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if VerifyOriented(algo, hl[0], root, steps, WithLevelHasher(levelHasher)) {
+			t.Errorf("proof should have been rejected by VerifyOriented")
+		}
+	})
+
+	t.Run("Should Rebuild On Update", func(t *testing.T) {
+		tree := NewTree(algo, hl, WithLevelHasher(levelHasher))
+		f := hashStringSlice(algo, "f")[0]
+		if err := tree.Update(hl[0], f); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		expected := NewTree(algo, [][]byte{f, hl[1], hl[2], hl[3], hl[4]}, WithLevelHasher(levelHasher)).Root().String()
+		if act := tree.Root().String(); act != expected {
+			t.Errorf("expected merkle root to be %s, got %s", expected, act)
+		}
+	})
+}
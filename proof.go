@@ -0,0 +1,213 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"hash"
+)
+
+// ErrUnpairedLeaf is returned when proving the parity of a leaf
+// which has no sibling at the bottom level, as it's promoted.
+var ErrUnpairedLeaf = errors.New("merkle: leaf has no sibling")
+
+// Proof is a merkle proof for a given leaf, that is, the steps needed
+// to reconstruct the merkle root from the leaf, from the bottom up.
+// Unlike plain Nodes, it carries the leaf and the merkle root
+// it proves against, making it a verifiable artifact on its own.
+type Proof struct {
+	leaf  []byte
+	root  []byte
+	steps Nodes
+	// the mode of the tree the proof was built from, along with
+	// the leaf index and tree size needed by positional modes.
+	mode        Mode
+	index, size int
+	// the name of the hashing algorithm, if known.
+	algo string
+	// whether root is the size commitment of the tree.
+	sized bool
+}
+
+// NewProof makes a new *Proof for leaf against root with the provided steps,
+// e.g. to wrap Nodes rehydrated with NodesFromHex.
+// The proof is expected to be built from a tree in ModeSorted.
+func NewProof(leaf, root []byte, steps Nodes) *Proof {
+	return &Proof{leaf: leaf, root: root, steps: steps}
+}
+
+// ProofOf builds and returns the *Proof for the provided hashed leaf.
+// It returns the same errors as Prove does.
+func (t Tree) ProofOf(hl []byte) (*Proof, error) {
+	steps, err := t.Prove(hl)
+	if err != nil {
+		return nil, err
+	}
+	p := NewProof(hl, t.publishedRoot(), steps)
+	p.mode = t.c.mode
+	p.index, _ = t.leafIndex(hl)
+	p.size = len(t.leaves)
+	p.algo = t.AlgoName()
+	p.sized = t.sized != nil
+	return p, nil
+}
+
+// Mode returns the mode of the tree the proof was built from.
+func (p Proof) Mode() Mode {
+	return p.mode
+}
+
+// AlgoName returns the name of the hashing algorithm of the tree
+// the proof was built from, empty if unknown, see Tree.AlgoName.
+func (p Proof) AlgoName() string {
+	return p.algo
+}
+
+// Leaf returns the proven leaf.
+func (p Proof) Leaf() []byte {
+	return p.leaf
+}
+
+// Root returns the merkle root the proof proves against.
+func (p Proof) Root() []byte {
+	return p.root
+}
+
+// Steps returns the proof Nodes, from the bottom up.
+func (p Proof) Steps() Nodes {
+	return p.steps
+}
+
+// Len returns the number of proof steps.
+func (p Proof) Len() int {
+	return len(p.steps)
+}
+
+// ToHexStrings converts each proof step into an hex string.
+func (p Proof) ToHexStrings() []string {
+	return p.steps.ToHexStrings()
+}
+
+// ToByteArrays converts each proof step into a slice of byte array.
+func (p Proof) ToByteArrays() [][]byte {
+	return p.steps.ToByteArrays()
+}
+
+// Verify verifies whether the proof is valid for a tree built with the
+// provided hashing algorithm and Option(s) in the mode of the proof.
+// Proofs naming a known hashing algorithm other than algo are invalid.
+func (p Proof) Verify(algo hash.Hash, opts ...Option) bool {
+	if !sameAlgo(p.algo, algo) {
+		return false
+	}
+	c := newConfig(append([]Option{WithMode(p.mode)}, opts...)...)
+	if p.sized {
+		root, ok := c.reconstructAt(algo, p.leaf, p.ToByteArrays(), p.index, p.size)
+		return ok && bytes.Equal(commitSize(algo, root, p.size), p.root)
+	}
+	return c.verifyAt(algo, p.leaf, p.root, p.ToByteArrays(), p.index, p.size)
+}
+
+// MarshalJSON implements the json.Marshaler interface,
+// it's marshalled the same way a Bundle is.
+func (p Proof) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Bundle{
+		Algo:  p.algo,
+		Mode:  p.mode,
+		Leaf:  p.leaf,
+		Root:  p.root,
+		Proof: p.ToByteArrays(),
+		Index: p.index,
+		Size:  p.size,
+		Sized: p.sized,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *Proof) UnmarshalJSON(data []byte) error {
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	p.leaf, p.root, p.steps = b.Leaf, b.Root, NodesFromBytes(b.Proof...)
+	p.mode, p.index, p.size, p.algo, p.sized = b.Mode, b.Index, b.Size, b.Algo, b.Sized
+	return nil
+}
+
+// ProofStep is a proof step annotated with its orientation, that is,
+// whether the sibling Hash is the left operand when hashing the pair.
+type ProofStep struct {
+	Hash   []byte
+	IsLeft bool
+}
+
+// ProofSteps builds and returns the merkle proof for the provided hashed
+// leaf with each sibling annotated with its orientation, from the bottom up.
+// This is needed to verify proofs of trees hashing children pairs by
+// position, e.g. ModeUnsorted, without knowing the leaf index.
+// Children of sorted trees are laid out in order as well, thus their
+// steps suit on-chain verifiers taking (bytes32 sibling, bool isLeft)
+// tuples rather than sorting pairs.
+// It returns the same errors as Prove does.
+func (t Tree) ProofSteps(hl []byte) ([]ProofStep, error) {
+	if _, err := t.Prove(hl); err != nil {
+		return nil, err
+	}
+	i, _ := t.leafIndex(hl)
+	steps := make([]ProofStep, 0, len(t.leaves)/2)
+	for n := t.leaves[i]; n != t.root; n = n.parent {
+		// a node paired with itself is both the left and
+		// the right child, thus its sibling is the right one.
+		steps = append(steps, ProofStep{Hash: n.Sibling().val, IsLeft: !n.IsLeft()})
+	}
+	return steps, nil
+}
+
+// ProveParity builds and returns the oriented proof steps of the provided
+// hashed leaf, same as ProofSteps does, alongside its parity, that is,
+// whether it's the right child of its bottom level pair. In positional
+// modes that's the lowest bit of its index, which is disclosed by the
+// orientation of the first step alone, see VerifyParity.
+// It returns the same errors as ProofSteps does and ErrUnpairedLeaf if the
+// leaf is promoted from the bottom level, as its first step belongs to an
+// upper level and it tells nothing about the parity.
+func (t Tree) ProveParity(hl []byte) (steps []ProofStep, odd bool, err error) {
+	steps, err = t.ProofSteps(hl)
+	if err != nil {
+		return nil, false, err
+	}
+	i, _ := t.leafIndex(hl)
+	if l := t.leaves[i]; l == t.root || !l.Sibling().IsLeaf() {
+		return nil, false, ErrUnpairedLeaf
+	}
+	return steps, steps[0].IsLeft, nil
+}
+
+// VerifyParity verifies whether the provided proof steps for leaf are
+// valid, same as VerifyOriented does, and that they prove the claimed
+// parity of the leaf, as built by ProveParity, that is, whether the
+// first sibling is the left one.
+func VerifyParity(algo hash.Hash, leaf, root []byte, steps []ProofStep, odd bool, opts ...Option) bool {
+	return len(steps) > 0 && steps[0].IsLeft == odd && VerifyOriented(algo, leaf, root, steps, opts...)
+}
+
+// VerifyOriented verifies whether the provided proof steps for leaf
+// are valid, hashing each pair according to the steps orientation
+// rather than sorting them, which suits any mode.
+// The hashing of pairs can be customised with Option(s), e.g. WithMode.
+// Steps don't tell their level, hence proofs for trees built
+// WithLevelHasher are rejected and need VerifyLevelHashed.
+func VerifyOriented(algo hash.Hash, leaf, root []byte, steps []ProofStep, opts ...Option) bool {
+	c := newConfig(opts...)
+	if c.levelHasher != nil {
+		return false
+	}
+	for _, s := range steps {
+		l, r := leaf, s.Hash
+		if s.IsLeft {
+			l, r = r, l
+		}
+		leaf = c.combine(algo, l, r, 0)
+	}
+	return bytes.Equal(leaf, root)
+}
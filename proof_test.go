@@ -0,0 +1,202 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+)
+
+func TestTree_ProofOf(t *testing.T) {
+	t.Run("With Non Existent Leaf Should Return ErrLeafNotFound", func(t *testing.T) {
+		if _, err := oddLeavesTree.ProofOf(hashStringSlice(algo, "f")[0]); err != ErrLeafNotFound {
+			t.Errorf("expected ErrLeafNotFound, got %v", err)
+		}
+	})
+	for leaf, expProof := range oddLeavesTreeProofs {
+		t.Run("Should Return Expected Proof For Leaf "+leaf, func(t *testing.T) {
+			leafb, _ := hex.DecodeString(leaf)
+			proof, err := oddLeavesTree.ProofOf(leafb)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !bytes.Equal(proof.Leaf(), leafb) || !bytes.Equal(proof.Root(), oddLeavesTree.root.val) {
+				t.Errorf("unexpected leaf %x or root %x", proof.Leaf(), proof.Root())
+			}
+			if proof.Len() != len(expProof) {
+				t.Fatalf("expected length of proof to be %d, got %d", len(expProof), proof.Len())
+			}
+			for i, h := range proof.ToHexStrings() {
+				if h != expProof[i] {
+					t.Errorf("expected node at index %d to be %s, got %s", i, expProof[i], h)
+				}
+			}
+			if !proof.Verify(algo) {
+				t.Errorf("proof should have been valid")
+			}
+		})
+	}
+}
+
+func TestProof_Verify(t *testing.T) {
+	proof, _ := oddLeavesTree.ProofOf(oddLeavesTree.leaves[0].val)
+	forged := NewProof(proof.Leaf(), evenLeavesTree.root.val, proof.Steps())
+	if forged.Verify(algo) {
+		t.Errorf("proof should have been invalid")
+	}
+}
+
+func TestProof_JSON(t *testing.T) {
+	proof, _ := oddLeavesTree.ProofOf(oddLeavesTree.leaves[0].val)
+	data, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var act Proof
+	if err := json.Unmarshal(data, &act); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if act.Len() != proof.Len() || !act.Verify(algo) {
+		t.Errorf("unmarshalled proof should have been valid")
+	}
+}
+
+func TestProof_AlgoName(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c")
+
+	t.Run("Should Carry Registered Name", func(t *testing.T) {
+		p, _ := NewTree(algo, hl).ProofOf(hl[0])
+		if p.AlgoName() != "sha256" {
+			t.Errorf("expected sha256, got %q", p.AlgoName())
+		}
+	})
+
+	t.Run("Should Round Trip Tree Name", func(t *testing.T) {
+		p, _ := NewTreeNamed("2.16.840.1.101.3.4.2.1", algo, hl).ProofOf(hl[0])
+		data, _ := json.Marshal(p)
+		var act Proof
+		if err := json.Unmarshal(data, &act); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if act.AlgoName() != "2.16.840.1.101.3.4.2.1" || !act.Verify(algo) {
+			t.Errorf("unmarshalled proof should have been valid, got name %q", act.AlgoName())
+		}
+	})
+
+	t.Run("Should Not Verify With Another Algorithm", func(t *testing.T) {
+		h := sha512.New()
+		hl := hashStringSlice(h, "a", "b", "c")
+		p, _ := NewTree(h, hl).ProofOf(hl[0])
+		if p.AlgoName() != "sha512" || p.Verify(algo) {
+			t.Errorf("proof should have been invalid with another algorithm")
+		}
+		if !p.Verify(h) {
+			t.Errorf("proof should have been valid")
+		}
+	})
+}
+
+func TestProof_Mode(t *testing.T) {
+	tree := NewTree(algo, hashStringSlice(algo, "a", "b", "c"), WithMode(ModeOrdered))
+	proof, _ := tree.ProofOf(tree.leaves[2].val)
+	data, _ := json.Marshal(proof)
+	var act Proof
+	if err := json.Unmarshal(data, &act); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if act.Mode() != ModeOrdered || !act.Verify(algo) {
+		t.Errorf("unmarshalled proof should have been valid in %s mode, got %s", ModeOrdered, act.Mode())
+	}
+}
+
+func TestTree_ProofSteps(t *testing.T) {
+	t.Run("With Non Existent Leaf Should Return ErrLeafNotFound", func(t *testing.T) {
+		if _, err := oddLeavesTree.ProofSteps(hashStringSlice(algo, "f")[0]); err != ErrLeafNotFound {
+			t.Errorf("expected ErrLeafNotFound, got %v", err)
+		}
+	})
+
+	for _, m := range []Mode{ModeSorted, ModeUnsorted, ModeOrdered, ModeRFC6962, ModeBitcoin} {
+		t.Run("Should Verify Oriented Steps In "+m.String()+" Mode", func(t *testing.T) {
+			for n := 1; n <= 9; n++ {
+				tree := NewTree(algo, hashStringSlice(algo, "a", "b", "c", "d", "e", "f", "g", "h", "i")[:n], WithMode(m))
+				for _, l := range tree.leaves {
+					steps, err := tree.ProofSteps(l.val)
+					if err != nil {
+						t.Fatalf("unexpected error %v", err)
+					}
+					if !VerifyOriented(algo, l.val, tree.Root().Bytes(), steps, WithMode(m)) {
+						t.Errorf("proof steps for leaf %s of %d leaves should have been valid", l, n)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTree_ProveParity(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+
+	for _, m := range []Mode{ModeOrdered, ModeRFC6962, ModeBitcoin} {
+		t.Run("Should Prove Index Parity In "+m.String()+" Mode", func(t *testing.T) {
+			tree := NewTree(algo, hl, WithMode(m))
+			for i, l := range hl {
+				steps, odd, err := tree.ProveParity(l)
+				if err == ErrUnpairedLeaf && m != ModeBitcoin && i == 4 {
+					continue
+				}
+				if err != nil {
+					t.Fatalf("unexpected error %v for leaf %d", err, i)
+				}
+				if odd != (i%2 == 1) {
+					t.Errorf("expected parity of leaf %d to be %t", i, i%2 == 1)
+				}
+				if !VerifyParity(algo, l, tree.Root().Bytes(), steps, odd, WithMode(m)) {
+					t.Errorf("parity of leaf %d should have been valid", i)
+				}
+				if VerifyParity(algo, l, tree.Root().Bytes(), steps, !odd, WithMode(m)) {
+					t.Errorf("parity of leaf %d should have been invalid when flipped", i)
+				}
+			}
+		})
+	}
+
+	t.Run("Should Return ErrUnpairedLeaf For Promoted Leaf", func(t *testing.T) {
+		tree := NewTree(algo, hl, WithMode(ModeOrdered))
+		if _, _, err := tree.ProveParity(hl[4]); err != ErrUnpairedLeaf {
+			t.Errorf("expected ErrUnpairedLeaf, got %v", err)
+		}
+	})
+
+	t.Run("With Non Existent Leaf Should Return ErrLeafNotFound", func(t *testing.T) {
+		if _, _, err := oddLeavesTree.ProveParity(hashStringSlice(algo, "f")[0]); err != ErrLeafNotFound {
+			t.Errorf("expected ErrLeafNotFound, got %v", err)
+		}
+	})
+}
+
+func TestWithMode_Unsorted(t *testing.T) {
+	leaves := hashStringSlice(algo, "a", "b", "c", "d", "e")
+	tree := NewTree(algo, leaves, WithMode(ModeUnsorted))
+
+	t.Run("Should Sort Leaves", func(t *testing.T) {
+		for i, l := range oddLeavesTree.leaves {
+			if !bytes.Equal(tree.leaves[i].val, l.val) {
+				t.Errorf("expected leaf at index %d to be %s, got %s", i, l, tree.leaves[i])
+			}
+		}
+	})
+
+	t.Run("Should Hash Pairs By Position", func(t *testing.T) {
+		sl := oddLeavesTree.leaves.ToByteArrays()
+		exp := combine(algo, combine(algo, combine(algo, sl[0], sl[1]), combine(algo, sl[2], sl[3])), sl[4])
+		if !bytes.Equal(tree.Root().Bytes(), exp) {
+			t.Errorf("expected merkle root to be %x, got %s", exp, tree.Root())
+		}
+	})
+
+	t.Run("Should Verify By Sorted Index", func(t *testing.T) {
+		verifyAllModeProofs(t, tree)
+	})
+}
@@ -0,0 +1,159 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"hash"
+)
+
+// ErrModeUnsupported is returned when a feature is not supported by the tree Mode.
+var ErrModeUnsupported = errors.New("merkle: unsupported mode")
+
+// RangeProof proves that a contiguous range of sorted leaves is committed
+// by a merkle root with no leaf missing in between.
+//
+// Leaves holds the leaves within the range bracketed by their closest
+// neighbors outside of it, if any, which prove that no leaf is missing
+// at the range edges. Start is the index of the first of the Leaves
+// within the Size sorted leaves of the tree, while Proof holds the
+// siblings needed to reconstruct the merkle root from the Leaves.
+type RangeProof struct {
+	Mode   Mode
+	Leaves [][]byte
+	Start  int
+	Size   int
+	Proof  [][]byte
+}
+
+// RangeProof builds and returns the *RangeProof for the leaves
+// within lo and hi inclusive. A range with no leaves at all is valid
+// as well, proving the absence of any leaf within lo and hi.
+// Returns ErrModeUnsupported for modes not sorting leaves
+// and ErrNoLeaves for trees built WithoutLeaves.
+func (t Tree) RangeProof(lo, hi []byte) (*RangeProof, error) {
+	if !t.c.mode.sortsLeaves() {
+		return nil, ErrModeUnsupported
+	}
+	if t.c.withoutLeaves {
+		return nil, ErrNoLeaves
+	}
+	t.expand()
+
+	n := len(t.leaves)
+	start, _ := t.leafIndex(lo)
+	end := start - 1
+	for end+1 < n && !t.c.less(hi, t.leaves[end+1].val) {
+		end++
+	}
+	// bracketing the range with its closest neighbors.
+	if start > 0 {
+		start--
+	}
+	if end+1 < n {
+		end++
+	}
+
+	rp := &RangeProof{
+		Mode:   t.c.mode,
+		Leaves: t.leaves[start : end+1].ToByteArrays(),
+		Start:  start,
+		Size:   n,
+	}
+	level := t.leaves
+	for a, b := start, end; len(level) > 1; a, b = a/2, b/2 {
+		if a%2 == 1 {
+			rp.Proof = append(rp.Proof, level[a-1].val)
+			a--
+		}
+		if b%2 == 0 && b+1 < len(level) {
+			rp.Proof = append(rp.Proof, level[b+1].val)
+			b++
+		}
+		// climbing one level up, pairs share the same
+		// parent while odd nodes are promoted as they are.
+		next := make(Nodes, 0, len(level)/2+1)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 < len(level) {
+				next = append(next, level[i].parent)
+			} else {
+				next = append(next, level[i])
+			}
+		}
+		level = next
+	}
+
+	return rp, nil
+}
+
+// VerifyRange verifies whether the provided *RangeProof is valid for the
+// range within lo and hi inclusive, returning the leaves within the range
+// which are then proven to be all and only the committed ones.
+// Trees built with custom Option(s), e.g. WithLess, need the same
+// Option(s) to be provided.
+func VerifyRange(algo hash.Hash, root, lo, hi []byte, rp *RangeProof, opts ...Option) ([][]byte, bool) {
+	c := newConfig(append([]Option{WithMode(rp.Mode)}, opts...)...)
+	if !c.mode.sortsLeaves() || len(rp.Leaves) == 0 || rp.Start < 0 || rp.Start+len(rp.Leaves) > rp.Size {
+		return nil, false
+	}
+
+	// leaves must be sorted and bracketed by leaves outside
+	// of the range unless the range reaches the tree edges.
+	for i := 1; i < len(rp.Leaves); i++ {
+		if c.less(rp.Leaves[i], rp.Leaves[i-1]) {
+			return nil, false
+		}
+	}
+	first, last := rp.Leaves[0], rp.Leaves[len(rp.Leaves)-1]
+	if rp.Start > 0 && !c.less(first, lo) {
+		return nil, false
+	}
+	if rp.Start+len(rp.Leaves) < rp.Size && !c.less(hi, last) {
+		return nil, false
+	}
+
+	level := make([][]byte, len(rp.Leaves))
+	copy(level, rp.Leaves)
+	proof := rp.Proof
+	for a, n := rp.Start, rp.Size; n > 1; a, n = a/2, (n+1)/2 {
+		if a%2 == 1 {
+			if len(proof) == 0 {
+				return nil, false
+			}
+			level = append([][]byte{proof[0]}, level...)
+			proof = proof[1:]
+			a--
+		}
+		if b := a + len(level) - 1; b%2 == 0 && b+1 < n {
+			if len(proof) == 0 {
+				return nil, false
+			}
+			level = append(level, proof[0])
+			proof = proof[1:]
+		}
+		next := make([][]byte, 0, len(level)/2+1)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				// promoting the odd node at the end of the level.
+				next = append(next, level[i])
+				continue
+			}
+			l, r := level[i], level[i+1]
+			if !c.mode.positional() {
+				l, r = c.order(l, r)
+			}
+			next = append(next, c.combine(algo, l, r, 0))
+		}
+		level = next
+	}
+	if len(level) != 1 || len(proof) > 0 || !bytes.Equal(level[0], root) {
+		return nil, false
+	}
+
+	members := make([][]byte, 0, len(rp.Leaves))
+	for _, l := range rp.Leaves {
+		if !c.less(l, lo) && !c.less(hi, l) {
+			members = append(members, l)
+		}
+	}
+	return members, true
+}
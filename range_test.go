@@ -0,0 +1,76 @@
+package merkle
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTree_RangeProof(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k")
+
+	for _, m := range []Mode{ModeSorted, ModeUnsorted} {
+		for n := 1; n <= len(hl); n++ {
+			tree := NewTree(algo, hl[:n], WithMode(m))
+			leaves := tree.leaves.ToByteArrays()
+			root := tree.Root().Bytes()
+			for i := 0; i < n; i++ {
+				for j := i; j < n; j++ {
+					rp, err := tree.RangeProof(leaves[i], leaves[j])
+					if err != nil {
+						t.Fatalf("unexpected error %v", err)
+					}
+					members, ok := VerifyRange(algo, root, leaves[i], leaves[j], rp)
+					if !ok {
+						t.Errorf("%s range proof [%d, %d] of %d leaves should have been valid", m, i, j, n)
+						continue
+					}
+					if len(members) != j-i+1 {
+						t.Errorf("expected %d leaves within range, got %d", j-i+1, len(members))
+					}
+					for k, l := range members {
+						if !bytes.Equal(l, leaves[i+k]) {
+							t.Errorf("expected leaf at %d to be %x, got %x", k, leaves[i+k], l)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	tree := NewTree(algo, hl)
+	leaves := tree.leaves.ToByteArrays()
+	root := tree.Root().Bytes()
+
+	t.Run("Should Prove Empty Range", func(t *testing.T) {
+		lo, hi := []byte{leaves[3][0], 0xff}, []byte{leaves[3][0], 0xff, 0xff}
+		rp, err := tree.RangeProof(lo, hi)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		members, ok := VerifyRange(algo, root, lo, hi, rp)
+		if !ok || len(members) != 0 {
+			t.Errorf("expected valid empty range, got %d leaves, %t", len(members), ok)
+		}
+	})
+
+	t.Run("Should Not Verify Range With A Missing Leaf", func(t *testing.T) {
+		rp, _ := tree.RangeProof(leaves[2], leaves[6])
+		rp.Leaves = append(rp.Leaves[:3:3], rp.Leaves[4:]...)
+		if _, ok := VerifyRange(algo, root, leaves[2], leaves[6], rp); ok {
+			t.Errorf("range proof should have been invalid")
+		}
+	})
+
+	t.Run("Should Not Verify Range Missing Bracketing Leaves", func(t *testing.T) {
+		rp, _ := tree.RangeProof(leaves[2], leaves[6])
+		if _, ok := VerifyRange(algo, root, leaves[1], leaves[6], rp); ok {
+			t.Errorf("range proof should have been invalid")
+		}
+	})
+
+	t.Run("Should Return ErrModeUnsupported", func(t *testing.T) {
+		if _, err := NewTree(algo, hl, WithMode(ModeOrdered)).RangeProof(leaves[0], leaves[1]); err != ErrModeUnsupported {
+			t.Errorf("expected ErrModeUnsupported, got %v", err)
+		}
+	})
+}
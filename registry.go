@@ -0,0 +1,117 @@
+package merkle
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"hash"
+	"reflect"
+	"sort"
+	"sync"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// ErrUnknownHash is returned when a hashing algorithm is not registered.
+var ErrUnknownHash = errors.New("merkle: unknown hashing algorithm")
+
+// hashes maps hashing algorithms names to their constructors, and
+// back from the algorithms, as told apart by hashKey, to their names.
+var hashes = struct {
+	sync.RWMutex
+	m     map[string]func() hash.Hash
+	names map[hashKey]string
+}{m: map[string]func() hash.Hash{
+	"md5":       md5.New,
+	"sha1":      sha1.New,
+	"sha224":    sha256.New224,
+	"sha256":    sha256.New,
+	"sha384":    sha512.New384,
+	"sha512":    sha512.New,
+	"keccak256": sha3.NewLegacyKeccak256,
+}}
+
+// RegisterHash registers the constructor of a hashing algorithm under the
+// provided name, so that self-describing artifacts such as a Bundle can
+// name the algorithm they've been hashed with, see VerifyBundleBytes.
+// The standard library md5, sha1, sha224, sha256, sha384 and sha512 are
+// registered by default, alongside the legacy keccak256 used by Ethereum.
+// Registering a name again overrides it. Algorithms registered under more
+// than one name, i.e. aliases, are named after the lexically first one.
+func RegisterHash(name string, newHash func() hash.Hash) {
+	hashes.Lock()
+	defer hashes.Unlock()
+	hashes.m[name] = newHash
+	indexHashes()
+}
+
+func init() {
+	indexHashes()
+}
+
+// hashKey tells hashing algorithms apart by their concrete
+// type and output size, e.g. sha224 from sha256.
+type hashKey struct {
+	typ  reflect.Type
+	size int
+}
+
+// keyOf returns the hashKey of the provided hashing algorithm.
+func keyOf(h hash.Hash) hashKey {
+	return hashKey{typ: reflect.TypeOf(h), size: h.Size()}
+}
+
+// indexHashes maps every registered algorithm back to its name, the
+// lexically first one for aliases, instantiating each algorithm once.
+// It must be called with the hashes lock held.
+func indexHashes() {
+	names := make([]string, 0, len(hashes.m))
+	for name := range hashes.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	hashes.names = make(map[hashKey]string, len(names))
+	for _, name := range names {
+		k := keyOf(hashes.m[name]())
+		if _, ok := hashes.names[k]; !ok {
+			hashes.names[k] = name
+		}
+	}
+}
+
+// HashByName returns a new instance of the hashing algorithm registered
+// under the provided name. It returns ErrUnknownHash if there is none.
+func HashByName(name string) (hash.Hash, error) {
+	hashes.RLock()
+	defer hashes.RUnlock()
+	newHash, ok := hashes.m[name]
+	if !ok {
+		return nil, ErrUnknownHash
+	}
+	return newHash(), nil
+}
+
+// hashName returns the name the provided hashing algorithm is registered
+// under, telling algorithms apart by their hashKey, e.g. sha224 from sha256.
+// It returns an empty string if there is none.
+func hashName(h hash.Hash) string {
+	hashes.RLock()
+	defer hashes.RUnlock()
+	return hashes.names[keyOf(h)]
+}
+
+// sameAlgo tells whether the provided hashing algorithm is the one named,
+// which holds as well whenever either of them is unknown, so that unnamed
+// artifacts and unregistered algorithms are not told apart at all.
+func sameAlgo(name string, h hash.Hash) bool {
+	if name == "" {
+		return true
+	}
+	named, err := HashByName(name)
+	if err != nil {
+		return true
+	}
+	return keyOf(named) == keyOf(h)
+}
@@ -0,0 +1,60 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+)
+
+func TestHashByName(t *testing.T) {
+	t.Run("Should Return Registered Hash", func(t *testing.T) {
+		h, err := HashByName("sha384")
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if h.Size() != sha512.Size384 {
+			t.Errorf("expected hash size to be %d, got %d", sha512.Size384, h.Size())
+		}
+	})
+
+	t.Run("Should Name Registered Hash", func(t *testing.T) {
+		if name := hashName(sha256.New224()); name != "sha224" {
+			t.Errorf("expected sha224, got %q", name)
+		}
+	})
+
+	t.Run("Should Register Keccak256", func(t *testing.T) {
+		h, err := HashByName("keccak256")
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		h.Write([]byte("abc"))
+		if exp, act := "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45", hex.EncodeToString(h.Sum(nil)); act != exp {
+			t.Errorf("expected legacy keccak256 %s, got %s", exp, act)
+		}
+	})
+
+	t.Run("Should Name Aliases After The First One", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			RegisterHash("sha512/224-b", sha512.New512_224)
+			RegisterHash("sha512/224-a", sha512.New512_224)
+			if name := hashName(sha512.New512_224()); name != "sha512/224-a" {
+				t.Fatalf("expected sha512/224-a, got %q", name)
+			}
+		}
+	})
+
+	t.Run("Should Register Custom Hash", func(t *testing.T) {
+		RegisterHash("sha512/256", sha512.New512_256)
+		if _, err := HashByName("sha512/256"); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+
+	t.Run("With Unknown Name Should Return ErrUnknownHash", func(t *testing.T) {
+		if _, err := HashByName("foo"); err != ErrUnknownHash {
+			t.Errorf("expected ErrUnknownHash, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package merkle
+
+import (
+	"bytes"
+	"hash"
+)
+
+// CombineRoots builds a tree over the provided roots of shards of leaves,
+// e.g. built on different machines, returning its merkle root. Roots are
+// paired and hashed the same way NewTree pairs and hashes nodes.
+//
+// The combined root matches the one of a single tree built over all of
+// the leaves only if shards respect the shape of such tree, that is, each
+// shard is made of the next leaves in sort order and every shard but the
+// last one has the same power of two number of leaves, which is what
+// ShardLeaves does. Otherwise the combined root is valid on its own right,
+// but it commits to the shards rather than to the flat set of leaves.
+func CombineRoots(h hash.Hash, subRoots [][]byte) []byte {
+	return foldLeaves(h, subRoots, newConfig())
+}
+
+// ShardLeaves splits the provided already sorted leaves into at most n
+// shards whose roots combine, with CombineRoots, into the same merkle root
+// of a tree built over all of the leaves. Every shard but the last one
+// has the same power of two number of leaves, thus fewer than n shards
+// may be returned. Shards share the memory of sortedLeaves.
+func ShardLeaves(sortedLeaves [][]byte, n int) [][][]byte {
+	if n < 1 {
+		n = 1
+	}
+	size := 1
+	for size*n < len(sortedLeaves) {
+		size <<= 1
+	}
+	shards := make([][][]byte, 0, n)
+	for lo := 0; lo < len(sortedLeaves); lo += size {
+		hi := lo + size
+		if hi > len(sortedLeaves) {
+			hi = len(sortedLeaves)
+		}
+		shards = append(shards, sortedLeaves[lo:hi:hi])
+	}
+	return shards
+}
+
+// VerifyChain verifies whether the provided chain of proofs proves leaf up
+// to topRoot across nested trees, e.g. a tree built over the roots of
+// subtrees. The first proof is folded from the leaf up to its subtree root,
+// which is then the leaf of the next proof, and so on up to the last proof,
+// which must reach topRoot. Proofs are ordered from the innermost tree out
+// and each is folded the same way Verify does.
+func VerifyChain(algo hash.Hash, leaf []byte, proofs [][][]byte, topRoot []byte) bool {
+	c := newConfig()
+	for _, proof := range proofs {
+		leaf = c.reconstruct(algo, leaf, proof)
+	}
+	return bytes.Equal(leaf, topRoot)
+}
@@ -0,0 +1,65 @@
+package merkle
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+func TestCombineRoots(t *testing.T) {
+	for size := 1; size <= 40; size++ {
+		data := make([]string, size)
+		for i := range data {
+			data[i] = strconv.Itoa(i)
+		}
+		tree := NewTree(algo, hashStringSlice(algo, data...))
+		exp := tree.Root().Bytes()
+		for n := 1; n <= 6; n++ {
+			shards := ShardLeaves(tree.leaves.ToByteArrays(), n)
+			if len(shards) > n {
+				t.Fatalf("expected at most %d shards, got %d", n, len(shards))
+			}
+			roots := make([][]byte, len(shards))
+			for i, s := range shards {
+				roots[i] = NewTree(algo, s).Root().Bytes()
+			}
+			if act := CombineRoots(algo, roots); !bytes.Equal(act, exp) {
+				t.Errorf("expected combined root of %d leaves in %d shards to be %x, got %x", size, n, exp, act)
+			}
+		}
+	}
+}
+
+func TestVerifyChain(t *testing.T) {
+	subtrees := []*Tree{
+		NewTree(algo, hashStringSlice(algo, "a", "b", "c")),
+		NewTree(algo, hashStringSlice(algo, "d", "e")),
+		NewTree(algo, hashStringSlice(algo, "f")),
+	}
+	roots := make([][]byte, len(subtrees))
+	for i, st := range subtrees {
+		roots[i] = st.Root().Bytes()
+	}
+	top := NewTree(algo, roots)
+
+	t.Run("Should Verify Leaves Up To Top Root", func(t *testing.T) {
+		for _, st := range subtrees {
+			topProof := top.Proof(st.Root().Bytes()).ToByteArrays()
+			for _, l := range st.leaves {
+				proofs := [][][]byte{st.Proof(l.val).ToByteArrays(), topProof}
+				if !VerifyChain(algo, l.val, proofs, top.Root().Bytes()) {
+					t.Errorf("chain of proofs for leaf %s should have been valid", l)
+				}
+			}
+		}
+	})
+
+	t.Run("Should Not Verify Out Of Order Chain", func(t *testing.T) {
+		st := subtrees[0]
+		l := st.leaves[0].val
+		proofs := [][][]byte{top.Proof(st.Root().Bytes()).ToByteArrays(), st.Proof(l).ToByteArrays()}
+		if VerifyChain(algo, l, proofs, top.Root().Bytes()) {
+			t.Errorf("chain of proofs should have been invalid")
+		}
+	})
+}
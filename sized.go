@@ -0,0 +1,60 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash"
+)
+
+// WithSizeCommitment makes Root return the commitment H(0x03 || root || size)
+// rather than the actual merkle root, size being the number of leaves as a
+// big endian uint64. This binds proofs to a specific tree size, preventing
+// a prover from presenting a proof against a tree of a different size than
+// claimed, see VerifySized. Proofs are the ones of the actual tree, while
+// ProveBundle, ProofOf and Snapshot publish the commitment as their root.
+// The 0x03 prefix tells it apart from the timestamp of the same root.
+func WithSizeCommitment() Option {
+	return func(c *config) {
+		c.sizeCommitment = true
+	}
+}
+
+// VerifySized verifies whether the provided proof for leaf is valid against
+// the sizedRoot commitment of a tree with the provided number of leaves,
+// built WithSizeCommitment, reconstructing the actual root, binding the
+// size to it and comparing. The Mode can be set with Option(s), in which
+// case positional modes need the leaf index, see VerifySizedAt.
+func VerifySized(algo hash.Hash, leaf, sizedRoot []byte, size int, proof [][]byte, opts ...Option) bool {
+	return VerifySizedAt(algo, leaf, sizedRoot, 0, size, proof, opts...)
+}
+
+// VerifySizedAt verifies whether the provided proof for the leaf at index
+// is valid against the sizedRoot commitment same as VerifySized does.
+func VerifySizedAt(algo hash.Hash, leaf, sizedRoot []byte, index, size int, proof [][]byte, opts ...Option) bool {
+	root, ok := newConfig(opts...).reconstructAt(algo, leaf, proof, index, size)
+	return ok && bytes.Equal(commitSize(algo, root, size), sizedRoot)
+}
+
+// the domains of the commitments of a root to an uint64, telling apart the
+// size commitment from the timestamp of the same root to the same value.
+const (
+	timestampDomain byte = 0x02
+	sizeDomain      byte = 0x03
+)
+
+// commitSize hashes the provided root with the number of leaves.
+func commitSize(h hash.Hash, root []byte, size int) []byte {
+	return commitUint64(h, sizeDomain, root, uint64(size))
+}
+
+// commitUint64 hashes the domain byte followed by
+// the root and v as a big endian uint64.
+func commitUint64(h hash.Hash, domain byte, root []byte, v uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	h.Reset()
+	h.Write([]byte{domain})
+	h.Write(root)
+	h.Write(b[:])
+	return h.Sum(nil)
+}
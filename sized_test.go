@@ -0,0 +1,127 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+func TestWithSizeCommitment(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+
+	t.Run("Should Commit Root To Size", func(t *testing.T) {
+		tree := NewTree(algo, hl, WithSizeCommitment())
+		exp := commitSize(algo, oddLeavesTree.Root().Bytes(), 5)
+		if !bytes.Equal(tree.Root().Bytes(), exp) {
+			t.Errorf("expected merkle root to be %x, got %s", exp, tree.Root())
+		}
+	})
+
+	t.Run("Should Commit Root To Size Without Leaves", func(t *testing.T) {
+		a := NewTree(algo, hl, WithSizeCommitment())
+		b := NewTree(algo, hl, WithSizeCommitment(), WithoutLeaves())
+		if a.Root().String() != b.Root().String() {
+			t.Errorf("expected merkle roots to be the same")
+		}
+	})
+
+	t.Run("Should Track Size On Insert", func(t *testing.T) {
+		tree := NewTree(algo, hl[:4], WithSizeCommitment())
+		tree.Insert(hl[4])
+		if exp := NewTree(algo, hl, WithSizeCommitment()); tree.Root().String() != exp.Root().String() {
+			t.Errorf("expected merkle root to be %s, got %s", exp.Root(), tree.Root())
+		}
+	})
+
+	for _, m := range []Mode{ModeSorted, ModeRFC6962} {
+		t.Run("Should Verify Sized Proofs In "+m.String()+" Mode", func(t *testing.T) {
+			tree := NewTree(algo, hl, WithMode(m), WithSizeCommitment())
+			root := tree.Root().Bytes()
+			for i, l := range tree.leaves {
+				proof := tree.Proof(l.val).ToByteArrays()
+				if !VerifySizedAt(algo, l.val, root, i, 5, proof, WithMode(m)) {
+					t.Errorf("sized proof for leaf %s should have been valid", l)
+				}
+				if VerifySizedAt(algo, l.val, root, i, 6, proof, WithMode(m)) {
+					t.Errorf("sized proof for leaf %s should have been invalid for a different size", l)
+				}
+			}
+		})
+	}
+
+	t.Run("Should Verify Sized Proofs", func(t *testing.T) {
+		tree := NewTree(algo, hl, WithSizeCommitment())
+		proof := tree.Proof(hl[0]).ToByteArrays()
+		if !VerifySized(algo, hl[0], tree.Root().Bytes(), 5, proof) {
+			t.Errorf("sized proof should have been valid")
+		}
+		if VerifySized(algo, hl[0], tree.Root().Bytes(), 4, proof) {
+			t.Errorf("sized proof should have been invalid for a different size")
+		}
+	})
+
+	t.Run("Should Differ From Timestamp Of Same Value", func(t *testing.T) {
+		tree := NewTree(algo, hl, WithSizeCommitment())
+		if bytes.Equal(tree.Root().Bytes(), NewTimestampedTree(algo, hl, 5).Root().Bytes()) {
+			t.Errorf("expected sized root to differ from the timestamped root")
+		}
+	})
+
+	t.Run("Should Track Root On Update", func(t *testing.T) {
+		tree := NewTree(algo, hl, WithSizeCommitment())
+		f := hashStringSlice(algo, "f")[0]
+		if err := tree.Update(hl[0], f); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		exp := NewTree(algo, [][]byte{f, hl[1], hl[2], hl[3], hl[4]}, WithSizeCommitment())
+		if tree.Root().String() != exp.Root().String() {
+			t.Errorf("expected merkle root to be %s, got %s", exp.Root(), tree.Root())
+		}
+	})
+
+	t.Run("Should Publish The Commitment", func(t *testing.T) {
+		tree := NewTree(algo, hl, WithSizeCommitment())
+		root := tree.Root().Bytes()
+
+		b, err := tree.ProveBundle(hl[0])
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if !bytes.Equal(b.Root, root) || !VerifyBundle(algo, b) {
+			t.Errorf("expected bundle to be valid against the sized root")
+		}
+		data, _ := json.Marshal(b)
+		if ok, err := VerifyBundleBytes(data, root); !ok || err != nil {
+			t.Errorf("expected bundle bytes to be valid, got %t, %v", ok, err)
+		}
+
+		p, err := tree.ProofOf(hl[0])
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if !bytes.Equal(p.Root(), root) || !p.Verify(algo) {
+			t.Errorf("expected proof to be valid against the sized root")
+		}
+
+		if act, _ := tree.Snapshot(); act != tree.Root().String() {
+			t.Errorf("expected snapshot root to be %s, got %s", tree.Root(), act)
+		}
+	})
+
+	t.Run("Should Return Root Concurrently", func(t *testing.T) {
+		tree := NewTree(algo, hl, WithSizeCommitment())
+		exp := tree.Root().String()
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if act := tree.Root().String(); act != exp {
+					t.Errorf("expected merkle root to be %s, got %s", exp, act)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,113 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"sort"
+)
+
+// Snapshot builds the merkle proofs for all of the tree leaves at once,
+// returning the hex merkle root alongside the proofs as hex strings
+// keyed by their hex leaf. This is the artifact one would publish
+// to allow anyone to verify inclusion on their own, e.g. an airdrop file.
+// For trees built WithSizeCommitment the root is the size commitment.
+// Equal leaves share their key, which holds the proof of the last of them,
+// any of their proofs being valid for the leaf in ModeSorted.
+//
+// Proofs of trees in positional modes can't be verified without the leaf
+// index, which isn't part of the snapshot, hence their proofs are nil,
+// e.g. ProveBundle makes self-contained proofs of such trees instead.
+//
+// Every node is hex encoded just once and shared across the proofs,
+// which is far cheaper than calling Proof for each of the leaves.
+func (t Tree) Snapshot() (root string, proofs map[string][]string) {
+	if t.c.mode.positional() {
+		return hex.EncodeToString(t.publishedRoot()), nil
+	}
+	t.expand()
+	hexs := make(map[*Node]string, len(t.leaves)*2)
+	t.root.WalkPreOrder(func(n *Node, _ int) {
+		hexs[n] = n.Hex()
+	})
+
+	proofs = make(map[string][]string, len(t.leaves))
+	for _, l := range t.leaves {
+		proof := make([]string, 0)
+		for n := l; n != t.root; n = n.parent {
+			proof = append(proof, hexs[n.Sibling()])
+		}
+		proofs[hexs[l]] = proof
+	}
+
+	return hex.EncodeToString(t.publishedRoot()), proofs
+}
+
+// WriteSnapshotJSON writes the proofs of all of the tree leaves to w as the
+// JSON object mapping each hex leaf to its hex proof, same as marshalling
+// the proofs of Snapshot would. Each proof is built and written one at a
+// time, keeping memory bounded whatever the number of leaves. Equal leaves
+// are written once with the proof of the last of them, as Snapshot keeps.
+// Writing errors are returned as is.
+// It returns ErrIncompatibleOptions for trees in positional modes, whose
+// proofs Snapshot leaves out.
+func (t Tree) WriteSnapshotJSON(w io.Writer) error {
+	if t.c.mode.positional() {
+		return ErrIncompatibleOptions
+	}
+	if _, err := io.WriteString(w, "{"); err != nil {
+		return err
+	}
+	t.expand()
+	enc := json.NewEncoder(w)
+	proof := make([]string, 0, height(len(t.leaves)))
+	sep := ""
+	for i, l := range t.leaves {
+		if i+1 < len(t.leaves) && bytes.Equal(l.val, t.leaves[i+1].val) {
+			continue
+		}
+		if _, err := io.WriteString(w, sep+`"`+l.Hex()+`":`); err != nil {
+			return err
+		}
+		sep = ","
+		proof = proof[:0]
+		for n := l; n != t.root; n = n.parent {
+			proof = append(proof, n.Sibling().Hex())
+		}
+		if err := enc.Encode(proof); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "}")
+	return err
+}
+
+// VerifySnapshot verifies every proof of the provided snapshot, as returned
+// by Tree.Snapshot, against root. It returns the hex leaves whose proof is
+// either invalid or not hex encoded, sorted, and whether all proofs are valid.
+// It lets publishers self-audit their inclusion files before distributing them.
+//
+// Proofs are verified same as VerifyWith does with the provided Option(s).
+// Snapshots are made of trees in ModeSorted only, hence it returns no bad
+// leaves and false for positional modes, e.g. WithMode(ModeRFC6962).
+func VerifySnapshot(algo hash.Hash, root []byte, snapshot map[string][]string, opts ...Option) (bad []string, ok bool) {
+	if newConfig(opts...).mode.positional() {
+		return nil, false
+	}
+	for leaf, proof := range snapshot {
+		l, err := hex.DecodeString(leaf)
+		if err != nil {
+			bad = append(bad, leaf)
+			continue
+		}
+		p, err := NodesFromHex(proof...)
+		if err != nil || !VerifyWith(algo, l, root, p.ToByteArrays(), opts...) {
+			bad = append(bad, leaf)
+		}
+	}
+	// sorting for a deterministic output despite the map iteration order.
+	sort.Strings(bad)
+	return bad, len(bad) == 0
+}
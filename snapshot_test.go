@@ -0,0 +1,171 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestTree_Snapshot(t *testing.T) {
+	root, proofs := oddLeavesTree.Snapshot()
+
+	t.Run("Should Return Hex Merkle Root", func(t *testing.T) {
+		if exp := oddLeavesTree.Root().Hex(); root != exp {
+			t.Errorf("expected merkle root to be %s, got %s", exp, root)
+		}
+	})
+
+	t.Run("Should Return Proofs For All Leaves", func(t *testing.T) {
+		if len(proofs) != len(oddLeavesTreeProofs) {
+			t.Errorf("expected %d proofs, got %d", len(oddLeavesTreeProofs), len(proofs))
+		}
+		for leaf, expProof := range oddLeavesTreeProofs {
+			actProof := proofs[leaf]
+			if len(expProof) != len(actProof) {
+				t.Errorf("expected length of proof for %s to be %d, got %d", leaf, len(expProof), len(actProof))
+				continue
+			}
+			for i := range actProof {
+				if actProof[i] != expProof[i] {
+					t.Errorf("expected node at index %d to be %s, got %s", i, expProof[i], actProof[i])
+				}
+			}
+		}
+	})
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestTree_WriteSnapshotJSON(t *testing.T) {
+	_, proofs := oddLeavesTree.Snapshot()
+	var buf bytes.Buffer
+	if err := oddLeavesTree.WriteSnapshotJSON(&buf); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	t.Run("Should Write Same Artifact As Snapshot", func(t *testing.T) {
+		var act map[string][]string
+		if err := json.Unmarshal(buf.Bytes(), &act); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if !reflect.DeepEqual(act, proofs) {
+			t.Errorf("expected proofs to be %v, got %v", proofs, act)
+		}
+	})
+
+	t.Run("Should Match Marshalled Snapshot Once Compacted", func(t *testing.T) {
+		exp, _ := json.Marshal(proofs)
+		var act bytes.Buffer
+		if err := json.Compact(&act, buf.Bytes()); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if !bytes.Equal(act.Bytes(), exp) {
+			t.Errorf("expected json to be %s, got %s", exp, act.Bytes())
+		}
+	})
+
+	t.Run("Should Keep The Last Proof Of Equal Leaves", func(t *testing.T) {
+		tree := NewTree(algo, hashStringSlice(algo, "a", "b", "a", "c", "a"))
+		root, proofs := tree.Snapshot()
+		if len(proofs) != 3 {
+			t.Fatalf("expected 3 proofs, got %d", len(proofs))
+		}
+		a := hashStringSlice(algo, "a")[0]
+		var last *Node
+		for _, l := range tree.leaves {
+			if bytes.Equal(l.val, a) {
+				last = l
+			}
+		}
+		exp := make([]string, 0)
+		for n := last; n != tree.root; n = n.parent {
+			exp = append(exp, n.Sibling().Hex())
+		}
+		if act := proofs[last.Hex()]; !reflect.DeepEqual(act, exp) {
+			t.Errorf("expected proof %v, got %v", exp, act)
+		}
+		r, _ := hex.DecodeString(root)
+		if bad, ok := VerifySnapshot(algo, r, proofs); !ok {
+			t.Errorf("expected snapshot to be valid, got bad leaves %v", bad)
+		}
+	})
+
+	t.Run("Should Write Equal Leaves Once", func(t *testing.T) {
+		tree := NewTree(algo, hashStringSlice(algo, "a", "b", "a", "c", "a"))
+		var buf bytes.Buffer
+		if err := tree.WriteSnapshotJSON(&buf); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if n := bytes.Count(buf.Bytes(), []byte(":")); n != 3 {
+			t.Errorf("expected 3 keys written, got %d", n)
+		}
+		var act map[string][]string
+		if err := json.Unmarshal(buf.Bytes(), &act); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if _, exp := tree.Snapshot(); !reflect.DeepEqual(act, exp) {
+			t.Errorf("expected %v, got %v", exp, act)
+		}
+	})
+
+	t.Run("Should Return Write Error", func(t *testing.T) {
+		if err := oddLeavesTree.WriteSnapshotJSON(failingWriter{}); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+}
+
+func TestVerifySnapshot(t *testing.T) {
+	root := oddLeavesTree.Root().Bytes()
+
+	t.Run("Should Be Verified", func(t *testing.T) {
+		_, proofs := oddLeavesTree.Snapshot()
+		if bad, ok := VerifySnapshot(algo, root, proofs); !ok || len(bad) > 0 {
+			t.Errorf("expected snapshot to be valid, got bad leaves %v", bad)
+		}
+	})
+
+	t.Run("Should Verify With Options", func(t *testing.T) {
+		tree := NewTree(algo, hashStringSlice(algo, "a", "b", "c"), WithLengthPrefix())
+		_, proofs := tree.Snapshot()
+		if _, ok := VerifySnapshot(algo, tree.Root().Bytes(), proofs, WithLengthPrefix()); !ok {
+			t.Errorf("expected snapshot to be valid")
+		}
+	})
+
+	t.Run("Should Reject Positional Modes", func(t *testing.T) {
+		for _, m := range []Mode{ModeRFC6962, ModeOrdered, ModeBitcoin} {
+			tree := NewTree(algo, hashStringSlice(algo, "a", "b", "c"), WithMode(m))
+			if _, proofs := tree.Snapshot(); proofs != nil {
+				t.Errorf("expected no proofs in mode %s, got %v", m, proofs)
+			}
+			if err := tree.WriteSnapshotJSON(io.Discard); err != ErrIncompatibleOptions {
+				t.Errorf("expected ErrIncompatibleOptions in mode %s, got %v", m, err)
+			}
+			_, proofs := oddLeavesTree.Snapshot()
+			if bad, ok := VerifySnapshot(algo, root, proofs, WithMode(m)); ok || bad != nil {
+				t.Errorf("expected snapshot to be rejected in mode %s, got bad leaves %v", m, bad)
+			}
+		}
+	})
+
+	t.Run("Should Return Bad Leaves", func(t *testing.T) {
+		_, proofs := oddLeavesTree.Snapshot()
+		leaves := oddLeavesTree.leaves.ToHexStrings()
+		proofs[leaves[0]] = proofs[leaves[1]]
+		proofs[leaves[2]] = []string{"zz"}
+		bad, ok := VerifySnapshot(algo, root, proofs)
+		if ok || len(bad) != 2 || bad[0] != leaves[0] || bad[1] != leaves[2] {
+			t.Errorf("expected bad leaves %v, got %v", []string{leaves[0], leaves[2]}, bad)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package merkle
+
+// ProofStats describes the proofs of a tree and how they're verified.
+type ProofStats struct {
+	// Min, Max and Avg are the lengths of the proofs.
+	Min, Max int
+	Avg      float64
+	// Padded is the length of every proof once the leaves are padded up
+	// to a power of two, e.g. WithEmptyHashPadding, balancing the tree.
+	Padded int
+	// OrientationBits is the number of bits the longest proof needs along
+	// with it to tell left siblings from right ones, that is, one per step
+	// in positional modes, e.g. derived from the leaf index, and none in
+	// ModeSorted, whose children pairs are sorted instead.
+	OrientationBits int
+	// Promoted and Duplicated are the numbers of odd nodes either carried
+	// up to the level above or paired with themselves, see ModeBitcoin.
+	Promoted, Duplicated int
+}
+
+// CompareModes computes the ProofStats each Mode would produce for a tree
+// with nLeaves leaves, which helps picking a scheme, e.g. for a gas budget.
+// It's purely computational, proofs lengths depend on the tree shape only,
+// which is the same for every Mode promoting odd nodes, hence these differ
+// by how their proofs are oriented rather than by their lengths.
+// Every ProofStats is zero for fewer than one leaf.
+func CompareModes(nLeaves int) map[Mode]ProofStats {
+	stats := make(map[Mode]ProofStats, len(modeNames))
+	for m := range modeNames {
+		stats[m] = proofStats(nLeaves, m)
+	}
+	return stats
+}
+
+// proofStats computes the ProofStats of a tree with size leaves
+// constructed with the provided Mode.
+func proofStats(size int, m Mode) ProofStats {
+	if size < 1 {
+		return ProofStats{}
+	}
+	duplicateOdd := m == ModeBitcoin
+	s := ProofStats{Min: -1, Padded: height(size)}
+	total := 0
+	for i := 0; i < size; i++ {
+		l := proofLen(i, size, duplicateOdd)
+		if s.Min < 0 || l < s.Min {
+			s.Min = l
+		}
+		if l > s.Max {
+			s.Max = l
+		}
+		total += l
+	}
+	s.Avg = float64(total) / float64(size)
+	if m.positional() {
+		s.OrientationBits = s.Max
+	}
+	for n := size; n > 1; n = (n + 1) / 2 {
+		if n%2 == 0 {
+			continue
+		}
+		if duplicateOdd {
+			s.Duplicated++
+		} else {
+			s.Promoted++
+		}
+	}
+	return s
+}
+
+// proofLen returns the length of the proof for the leaf at index
+// within size leaves, either promoting or duplicating odd nodes.
+func proofLen(index, size int, duplicateOdd bool) int {
+	l := 0
+	for n := size; n > 1; n = (n + 1) / 2 {
+		if index%2 == 1 || index+1 < n || duplicateOdd {
+			l++
+		}
+		index /= 2
+	}
+	return l
+}
@@ -0,0 +1,70 @@
+package merkle
+
+import (
+	"testing"
+)
+
+func TestCompareModes(t *testing.T) {
+	t.Run("Should Match Actual Proofs Lengths", func(t *testing.T) {
+		hl := hashStringSlice(algo, "a", "b", "c", "d", "e", "f", "g", "h", "i")
+		for n := 1; n <= len(hl); n++ {
+			for m, act := range CompareModes(n) {
+				tree := NewTree(algo, hl[:n], WithMode(m))
+				exp := act
+				exp.Min, exp.Max = -1, 0
+				total := 0
+				for _, l := range tree.leaves {
+					pl := len(tree.Proof(l.val))
+					if exp.Min < 0 || pl < exp.Min {
+						exp.Min = pl
+					}
+					if pl > exp.Max {
+						exp.Max = pl
+					}
+					total += pl
+				}
+				exp.Avg = float64(total) / float64(n)
+				if act != exp {
+					t.Errorf("expected %s stats with %d leaves to be %+v, got %+v", m, n, exp, act)
+				}
+			}
+		}
+	})
+
+	t.Run("Should Match Actual Padded Proofs Lengths", func(t *testing.T) {
+		hl := hashStringSlice(algo, "a", "b", "c", "d", "e", "f", "g", "h", "i")
+		for n := 1; n <= len(hl); n++ {
+			for m, act := range CompareModes(n) {
+				tree := NewTree(algo, hl[:n], WithMode(m), WithEmptyHashPadding())
+				for _, l := range hl[:n] {
+					if exp := len(tree.Proof(l)); act.Padded != exp {
+						t.Errorf("expected %s padded proofs with %d leaves to be %d long, got %d", m, n, exp, act.Padded)
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("Should Return Odd Leaves Tree Stats", func(t *testing.T) {
+		stats := CompareModes(5)
+		for m, exp := range map[Mode]ProofStats{
+			ModeSorted:   {Min: 1, Max: 3, Avg: 2.6, Padded: 3, Promoted: 2},
+			ModeOrdered:  {Min: 1, Max: 3, Avg: 2.6, Padded: 3, OrientationBits: 3, Promoted: 2},
+			ModeBitcoin:  {Min: 3, Max: 3, Avg: 3, Padded: 3, OrientationBits: 3, Duplicated: 2},
+			ModeRFC6962:  {Min: 1, Max: 3, Avg: 2.6, Padded: 3, OrientationBits: 3, Promoted: 2},
+			ModeUnsorted: {Min: 1, Max: 3, Avg: 2.6, Padded: 3, OrientationBits: 3, Promoted: 2},
+		} {
+			if act := stats[m]; act != exp {
+				t.Errorf("expected %s stats to be %+v, got %+v", m, exp, act)
+			}
+		}
+	})
+
+	t.Run("Should Return Zero Stats Without Leaves", func(t *testing.T) {
+		for m, act := range CompareModes(0) {
+			if act != (ProofStats{}) {
+				t.Errorf("expected %s stats to be zero, got %+v", m, act)
+			}
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"hash"
+	"io"
+	"sort"
+)
+
+// RootFromLeaves computes the merkle root of the provided already sorted
+// leaves without allocating any Node, folding them level by level within
+// a single buffer. It equals the root of a Tree built with the same
+// hashing algorithm, leaves and Option(s), nil if no leaves are provided.
+//
+// Leaves are neither padded nor collapsed, hence it returns nil as well for
+// Option(s) only a Tree applies, that is, WithEmptyHashPadding,
+// WithBlindingPadding, WithSizeCommitment, WithMultiplicities and
+// WithOddHandler.
+func RootFromLeaves(h hash.Hash, sortedLeaves [][]byte, opts ...Option) []byte {
+	c := newConfig(opts...)
+	if !c.foldable() {
+		return nil
+	}
+	return foldLeaves(h, sortedLeaves, c)
+}
+
+// foldable tells whether folding leaves straight to the root, as done by
+// RootFromLeaves, makes the root of a Tree built with the config, which
+// doesn't hold once padding or collapsing leaves, committing to their
+// number or handing odd Nodes to an OddHandler.
+func (c *config) foldable() bool {
+	return c.padding < 1 && !c.emptyPadding && !c.sizeCommitment && !c.multiplicities && c.oddHandler == nil
+}
+
+// RootCustom computes the merkle root of the provided leaves following
+// caller supplied rules only, which is meant for parity tests against other
+// implementations, checking whether any Mode reproduces their roots.
+// order sorts a copy of the leaves in place, they're kept in the provided
+// order if nil. Each level is paired from left to right, hashing each
+// pair with combine(left, right), and an odd node at the end of a level
+// is carried up as returned by oddHandler, as is if nil.
+// It returns nil if no leaves are provided.
+func RootCustom(leaves [][]byte, combine func(a, b []byte) []byte, order func([][]byte), oddHandler func([]byte) []byte) []byte {
+	if len(leaves) == 0 {
+		return nil
+	}
+	level := make([][]byte, len(leaves))
+	copy(level, leaves)
+	if order != nil {
+		order(level)
+	}
+	for len(level) > 1 {
+		// parents are written in place as they never
+		// overtake the pair being currently hashed.
+		for k := 0; k+1 < len(level); k += 2 {
+			level[k/2] = combine(level[k], level[k+1])
+		}
+		if last := len(level) - 1; last%2 == 0 {
+			level[last/2] = level[last]
+			if oddHandler != nil {
+				level[last/2] = oddHandler(level[last])
+			}
+		}
+		level = level[:(len(level)+1)/2]
+	}
+	return level[0]
+}
+
+// VerifyLeafSet verifies whether the provided hashed leaves, and nothing
+// else, make up the tree with the provided root, proving completeness
+// rather than inclusion, e.g. a published leaves file matching a committed
+// root. Leaves are sorted, same as the tree does, without being modified.
+// It returns false if no leaves are provided, as well as for the Option(s)
+// RootFromLeaves doesn't support.
+func VerifyLeafSet(h hash.Hash, leaves [][]byte, root []byte, opts ...Option) bool {
+	c := newConfig(opts...)
+	if !c.foldable() {
+		return false
+	}
+	sorted := leaves
+	if c.mode.sortsLeaves() {
+		sorted = make([][]byte, len(leaves))
+		copy(sorted, leaves)
+		sort.Slice(sorted, func(i, j int) bool {
+			return c.less(sorted[i], sorted[j])
+		})
+	}
+	implied := foldLeaves(h, sorted, c)
+	return implied != nil && bytes.Equal(implied, root)
+}
+
+// RootFromReader computes the merkle root same as RootFromLeaves does,
+// streaming the already sorted leaves from r as concatenated hashes of the
+// hashing algorithm size. Only the roots of the complete subtrees seen so
+// far are held, that is, a logarithmic number of hashes, which makes it
+// suitable for sets of leaves far larger than the available memory.
+//
+// It returns ErrHashSize if r ends in the middle of a leaf,
+// ErrNoLeaves if r has no leaves at all and ErrIncompatibleOptions
+// for the Option(s) RootFromLeaves doesn't support.
+func RootFromReader(h hash.Hash, r io.Reader, opts ...Option) ([]byte, error) {
+	c := newConfig(opts...)
+	if !c.foldable() {
+		return nil, ErrIncompatibleOptions
+	}
+	var stack []streamNode
+	for {
+		leaf := make([]byte, h.Size())
+		if _, err := io.ReadFull(r, leaf); errors.Is(err, io.EOF) {
+			break
+		} else if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrHashSize
+		} else if err != nil {
+			return nil, err
+		}
+		stack = append(stack, streamNode{val: leaf, size: 1})
+		// merging complete subtrees of the same height as they would
+		// be paired by the time they're on the same level.
+		for len(stack) > 1 && stack[len(stack)-1].level == stack[len(stack)-2].level {
+			stack = append(stack[:len(stack)-2], c.mergeStream(h, stack[len(stack)-2], stack[len(stack)-1]))
+		}
+	}
+	if len(stack) == 0 {
+		return nil, ErrNoLeaves
+	}
+
+	// merging what's left from the right, the lone subtrees at the end
+	// of the tree get either promoted or paired with themselves up to
+	// the height of their left sibling.
+	for len(stack) > 1 {
+		l, r := stack[len(stack)-2], stack[len(stack)-1]
+		for c.duplicateOdd && r.level < l.level {
+			r = streamNode{val: c.combine(c.hasher(h, r.level), r.val, r.val, 0), level: r.level + 1, size: r.size}
+		}
+		stack = append(stack[:len(stack)-2], c.mergeStream(h, l, r))
+	}
+	return stack[0].val, nil
+}
+
+// streamNode is the root of a complete subtree seen by RootFromReader.
+type streamNode struct {
+	val []byte
+	// level is the height of the subtree, 0 for a leaf.
+	level int
+	// size is the number of leaves under the subtree.
+	size int
+}
+
+// mergeStream hashes the provided pair of subtrees roots into their parent,
+// with the algorithm of the level they're paired at, same as buildTree.
+func (c *config) mergeStream(h hash.Hash, l, r streamNode) streamNode {
+	size := l.size + r.size
+	lv, rv := l.val, r.val
+	if !c.mode.positional() {
+		lv, rv = c.order(lv, rv)
+	}
+	return streamNode{val: c.combine(c.hasher(h, l.level), lv, rv, size), level: l.level + 1, size: size}
+}
@@ -0,0 +1,178 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestRootFromLeaves(t *testing.T) {
+	for _, tree := range []*Tree{oddLeavesTree, evenLeavesTree} {
+		exp := tree.Root().Bytes()
+		if act := RootFromLeaves(algo, tree.leaves.ToByteArrays()); !bytes.Equal(act, exp) {
+			t.Errorf("expected merkle root should have been %x, got %x", exp, act)
+		}
+	}
+}
+
+func TestRootCustom(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+	concat := func(a, b []byte) []byte {
+		return combine(algo, a, b)
+	}
+	sorted := func(a, b []byte) []byte {
+		if bytes.Compare(b, a) < 0 {
+			a, b = b, a
+		}
+		return combine(algo, a, b)
+	}
+	sortLeaves := func(ls [][]byte) {
+		sort.Slice(ls, func(i, j int) bool {
+			return bytes.Compare(ls[i], ls[j]) < 0
+		})
+	}
+	double := func(a, b []byte) []byte {
+		return rehash(algo, combine(algo, a, b))
+	}
+
+	for name, tc := range map[string]struct {
+		combine func(a, b []byte) []byte
+		order   func([][]byte)
+		odd     func([]byte) []byte
+		mode    Mode
+	}{
+		"Sorted":  {sorted, sortLeaves, nil, ModeSorted},
+		"Ordered": {concat, nil, nil, ModeOrdered},
+		"Bitcoin": {double, nil, func(x []byte) []byte { return double(x, x) }, ModeBitcoin},
+	} {
+		t.Run("Should Reproduce "+name+" Mode", func(t *testing.T) {
+			exp := NewTree(algo, hl, WithMode(tc.mode)).Root().Bytes()
+			if act := RootCustom(hl, tc.combine, tc.order, tc.odd); !bytes.Equal(act, exp) {
+				t.Errorf("expected merkle root to be %x, got %x", exp, act)
+			}
+		})
+	}
+
+	t.Run("Should Not Modify Leaves", func(t *testing.T) {
+		RootCustom(hl, sorted, sortLeaves, nil)
+		if !bytes.Equal(hl[0], hashStringSlice(algo, "a")[0]) {
+			t.Errorf("expected leaves to be left untouched")
+		}
+	})
+
+	t.Run("Should Return Nil Without Leaves", func(t *testing.T) {
+		if act := RootCustom(nil, concat, nil, nil); act != nil {
+			t.Errorf("expected nil, got %x", act)
+		}
+	})
+}
+
+func TestVerifyLeafSet(t *testing.T) {
+	hl := hashStringSlice(algo, "e", "c", "a", "d", "b")
+	root := oddLeavesTree.Root().Bytes()
+
+	t.Run("Should Verify Unsorted Complete Set", func(t *testing.T) {
+		if !VerifyLeafSet(algo, hl, root) {
+			t.Errorf("leaf set should have been valid")
+		}
+	})
+
+	t.Run("Should Not Modify Leaves", func(t *testing.T) {
+		if !bytes.Equal(hl[0], hashStringSlice(algo, "e")[0]) {
+			t.Errorf("expected leaves to be left untouched")
+		}
+	})
+
+	t.Run("Should Not Verify Incomplete Or Extra Set", func(t *testing.T) {
+		for _, set := range [][][]byte{hl[:4], append(hashStringSlice(algo, "f"), hl...), nil} {
+			if VerifyLeafSet(algo, set, root) {
+				t.Errorf("leaf set of %d leaves should have been invalid", len(set))
+			}
+		}
+	})
+
+	t.Run("Should Keep Order In Positional Modes", func(t *testing.T) {
+		tree := NewTree(algo, hl, WithMode(ModeRFC6962))
+		if !VerifyLeafSet(algo, hl, tree.Root().Bytes(), WithMode(ModeRFC6962)) {
+			t.Errorf("leaf set should have been valid")
+		}
+	})
+}
+
+func TestRootFromReader(t *testing.T) {
+	sized := WithSizedCombine(func(h hash.Hash, l, r []byte, size int) []byte {
+		h.Reset()
+		binary.Write(h, binary.BigEndian, uint64(size))
+		h.Write(l)
+		h.Write(r)
+		return h.Sum(nil)
+	})
+	for name, opts := range map[string][]Option{
+		"Default":  nil,
+		"Ordered":  {WithMode(ModeOrdered)},
+		"Bitcoin":  {WithMode(ModeBitcoin)},
+		"RFC6962":  {WithMode(ModeRFC6962)},
+		"Sized":    {sized},
+		"Unsorted": {WithMode(ModeUnsorted)},
+	} {
+		t.Run("Should Return Same Root As Tree In "+name, func(t *testing.T) {
+			for n := 1; n <= 33; n++ {
+				data := make([]string, n)
+				for i := range data {
+					data[i] = strconv.Itoa(i)
+				}
+				tree := NewTree(algo, hashStringSlice(algo, data...), opts...)
+				exp := tree.Root().Bytes()
+				act, err := RootFromReader(algo, bytes.NewReader(bytes.Join(tree.leaves.ToByteArrays(), nil)), opts...)
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				if !bytes.Equal(act, exp) {
+					t.Errorf("expected merkle root of %d leaves should have been %x, got %x", n, exp, act)
+				}
+			}
+		})
+	}
+
+	t.Run("Should Return ErrHashSize For Partial Leaf", func(t *testing.T) {
+		b := bytes.Join(oddLeavesTree.leaves.ToByteArrays(), nil)
+		if _, err := RootFromReader(algo, bytes.NewReader(b[:len(b)-1])); err != ErrHashSize {
+			t.Errorf("expected ErrHashSize, got %v", err)
+		}
+	})
+
+	t.Run("Should Return ErrNoLeaves For Empty Reader", func(t *testing.T) {
+		if _, err := RootFromReader(algo, bytes.NewReader(nil)); err != ErrNoLeaves {
+			t.Errorf("expected ErrNoLeaves, got %v", err)
+		}
+	})
+}
+
+func TestRootFromLeaves_UnsupportedOptions(t *testing.T) {
+	hl := evenLeavesTree.leaves.ToByteArrays()
+	root := evenLeavesTree.Root().Bytes()
+	for name, opt := range map[string]Option{
+		"Empty Padding":    WithEmptyHashPadding(),
+		"Blinding Padding": WithBlindingPadding(8, nil),
+		"Size Commitment":  WithSizeCommitment(),
+		"Multiplicities":   WithMultiplicities(),
+		"Odd Handler": WithOddHandler(func(odd *Node, _ int) *Node {
+			return odd
+		}),
+	} {
+		t.Run("With "+name+" Should Reject Leaves", func(t *testing.T) {
+			if act := RootFromLeaves(algo, hl, opt); act != nil {
+				t.Errorf("expected nil merkle root, got %x", act)
+			}
+			if VerifyLeafSet(algo, hl, root, opt) {
+				t.Errorf("expected leaf set to be rejected")
+			}
+			if _, err := RootFromReader(algo, bytes.NewReader(bytes.Join(hl, nil)), opt); err != ErrIncompatibleOptions {
+				t.Errorf("expected ErrIncompatibleOptions, got %v", err)
+			}
+		})
+	}
+}
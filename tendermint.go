@@ -0,0 +1,23 @@
+package merkle
+
+import (
+	"hash"
+)
+
+// NewTendermintTree builds up a new merkle tree out of the raw items the
+// same way Tendermint's HashFromByteSlices does, so that its root and proofs
+// match the ones of Tendermint and Cosmos, e.g. block and commit hashes.
+//
+// Tendermint trees are RFC 6962 trees, that is, items are kept in the
+// provided order, leaves are hashed with a 0x00 prefix and inner nodes
+// with a 0x01 prefix, thus the tree is built in ModeRFC6962.
+// Proofs are Tendermint's aunts, which can be verified with VerifyMode
+// providing the proof index and total as index and size.
+// The root of no items at all is the hash of nothing, as for Tendermint.
+func NewTendermintTree(h hash.Hash, items [][]byte) *Tree {
+	if len(items) == 0 {
+		h.Reset()
+		return &Tree{root: newNode(h.Sum(nil)), h: h, c: newConfig(WithMode(ModeRFC6962))}
+	}
+	return NewTreeFromData(h, items, WithMode(ModeRFC6962))
+}
@@ -0,0 +1,36 @@
+package merkle
+
+import (
+	"testing"
+)
+
+// TestNewTendermintTree checks the vectors of Tendermint's
+// crypto/merkle TestHashFromByteSlices.
+func TestNewTendermintTree(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		items [][]byte
+		exp   string
+	}{
+		{"Nil", nil, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"Single", [][]byte{{1, 2, 3}}, "054edec1d0211f624fed0cbca9d4f9400b0e491c43742af2c5b0abebf0c990d8"},
+		{"Single Blank", [][]byte{{}}, "6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d"},
+		{"Two", [][]byte{{1, 2, 3}, {4, 5, 6}}, "82e6cfce00453804379b53962939eaa7906b39904be0813fcadd31b100773c4b"},
+		{"Many", [][]byte{{1, 2}, {3, 4}, {5, 6}, {7, 8}, {9, 10}}, "f326493eceab4f2d9ffbc78c59432a0a005d6ea98392045c74df5d14a113be18"},
+	} {
+		tree := NewTendermintTree(algo, tc.items)
+		t.Run("Should Return Expected Root For "+tc.name, func(t *testing.T) {
+			if act := tree.Root().Hex(); act != tc.exp {
+				t.Errorf("expected merkle root should have been %s, got %s", tc.exp, act)
+			}
+		})
+		t.Run("Should Verify Aunts For "+tc.name, func(t *testing.T) {
+			for i, l := range tree.leaves {
+				aunts := tree.Proof(l.val).ToByteArrays()
+				if !VerifyMode(algo, ModeRFC6962, l.val, tree.Root().Bytes(), aunts, i, len(tc.items)) {
+					t.Errorf("aunts of item %d should have been valid", i)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package merkle
+
+import (
+	"bytes"
+	"hash"
+)
+
+// TimestampedTree is a Tree whose merkle root commits to an epoch as well,
+// so that the same set of leaves yields different roots at different
+// epochs, binding proofs to a point in time.
+//
+// The timestamped root is the hash of the 0x02 byte, the tree merkle root
+// and the epoch as a big endian uint64, proofs are the ones of the
+// underlying Tree. The prefix tells it apart from size commitments.
+type TimestampedTree struct {
+	*Tree
+	root  *Node
+	epoch int64
+}
+
+// NewTimestampedTree makes a new TimestampedTree with the provided hashing
+// algorithm, set of hashed leaves and epoch, e.g. a unix timestamp.
+func NewTimestampedTree(h hash.Hash, hl [][]byte, epoch int64, opts ...Option) *TimestampedTree {
+	t := &TimestampedTree{Tree: NewTree(h, hl, opts...), epoch: epoch}
+	t.stamp()
+	return t
+}
+
+// Insert inserts the provided hashed leaf same as Tree.Insert does,
+// timestamping the new merkle root at the same epoch.
+// It's not safe for concurrent use.
+func (t *TimestampedTree) Insert(hl []byte) error {
+	if err := t.Tree.Insert(hl); err != nil {
+		return err
+	}
+	t.stamp()
+	return nil
+}
+
+// Update replaces the old hashed leaf with the new one same as Tree.Update
+// does, timestamping the new merkle root at the same epoch.
+// It's not safe for concurrent use.
+func (t *TimestampedTree) Update(old, new []byte) error {
+	if err := t.Tree.Update(old, new); err != nil {
+		return err
+	}
+	t.stamp()
+	return nil
+}
+
+// stamp computes the timestamped root out of the current merkle root,
+// once per root so that Root doesn't hash, hence it's safe for concurrent use.
+func (t *TimestampedTree) stamp() {
+	defer t.c.lock()()
+	t.root = newNode(timestamp(t.h, t.Tree.root.val, t.epoch))
+}
+
+// Root returns the timestamped root *Node, which has no children.
+// The merkle root of the leaves is still available through Tree.Root.
+// Changing the tree through the embedded Tree rather than through Insert
+// and Update of the TimestampedTree leaves the timestamped root behind.
+func (t TimestampedTree) Root() *Node {
+	return t.root
+}
+
+// Epoch returns the epoch the tree root commits to.
+func (t TimestampedTree) Epoch() int64 {
+	return t.epoch
+}
+
+// VerifyTimestamped verifies whether the provided proof for leaf is
+// valid for a TimestampedTree with the provided root and epoch, same as
+// Verify it assumes the tree was built with the default Option(s).
+func VerifyTimestamped(algo hash.Hash, leaf, root []byte, proof [][]byte, epoch int64) bool {
+	// an empty proof reconstructs the leaf itself being the tree root.
+	r := newConfig().reconstruct(algo, leaf, proof)
+	return bytes.Equal(timestamp(algo, r, epoch), root)
+}
+
+// timestamp hashes the provided merkle root alongside the epoch.
+func timestamp(h hash.Hash, root []byte, epoch int64) []byte {
+	return commitUint64(h, timestampDomain, root, uint64(epoch))
+}
@@ -0,0 +1,62 @@
+package merkle
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewTimestampedTree(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+	tree := NewTimestampedTree(algo, hl, 1700000000)
+
+	t.Run("Should Commit To The Epoch", func(t *testing.T) {
+		if bytes.Equal(tree.Root().Bytes(), oddLeavesTree.Root().Bytes()) {
+			t.Errorf("expected timestamped root to differ from the merkle root")
+		}
+		if bytes.Equal(tree.Root().Bytes(), NewTimestampedTree(algo, hl, 1700000001).Root().Bytes()) {
+			t.Errorf("expected roots at different epochs to differ")
+		}
+		if tree.Tree.Root().String() != oddLeavesTree.Root().String() {
+			t.Errorf("expected underlying merkle root to be %s", oddLeavesTree.Root())
+		}
+	})
+
+	t.Run("Should Verify Proofs At The Same Epoch Only", func(t *testing.T) {
+		root := tree.Root().Bytes()
+		for _, l := range hl {
+			proof := tree.Proof(l).ToByteArrays()
+			if !VerifyTimestamped(algo, l, root, proof, tree.Epoch()) {
+				t.Errorf("proof for %x should have been valid", l)
+			}
+			if VerifyTimestamped(algo, l, root, proof, tree.Epoch()+1) {
+				t.Errorf("proof for %x should have been invalid at a different epoch", l)
+			}
+		}
+	})
+
+	t.Run("Should Verify Single Leaf Tree", func(t *testing.T) {
+		single := NewTimestampedTree(algo, hl[:1], 42)
+		if !VerifyTimestamped(algo, hl[0], single.Root().Bytes(), nil, 42) {
+			t.Errorf("empty proof should have been valid")
+		}
+	})
+
+	t.Run("Should Timestamp The Changed Root", func(t *testing.T) {
+		tree := NewTimestampedTree(algo, hl[:4], 42)
+		tree.Insert(hl[4])
+		if exp := NewTimestampedTree(algo, hl, 42).Root().String(); tree.Root().String() != exp {
+			t.Errorf("expected timestamped root after Insert to be %s, got %s", exp, tree.Root())
+		}
+		f := hashStringSlice(algo, "f")[0]
+		if err := tree.Update(hl[0], f); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		exp := NewTimestampedTree(algo, [][]byte{f, hl[1], hl[2], hl[3], hl[4]}, 42).Root().String()
+		if tree.Root().String() != exp {
+			t.Errorf("expected timestamped root after Update to be %s, got %s", exp, tree.Root())
+		}
+		if err := tree.Update(hl[0], f); err != ErrLeafNotFound {
+			t.Errorf("expected ErrLeafNotFound, got %v", err)
+		}
+	})
+}
@@ -21,56 +21,599 @@
 // you should look elsewhere as their implementation may be different.
 // For example, Bitcoin's merkle, duplicates eventual odd nodes to re-balance the tree
 // and this implementation doesn't, thus producing a different merkle root and proof.
+//
+// As odd nodes are promoted unchanged, a level where a node is promoted
+// has no sibling to be combined with. Proofs simply omit such levels
+// rather than encoding a placeholder, thus proofs may be shorter than
+// the tree height. This is the canonical proof encoding of this package.
 package merkle
 
 import (
 	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"hash"
+	"math/bits"
 	"sort"
+	"strings"
+	"sync"
 )
 
+// ErrLeafNotFound is returned when the provided leaf is not part of the tree.
+var ErrLeafNotFound = errors.New("merkle: leaf not found")
+
+// ErrNoLeaves is returned when proving a leaf of a tree built WithoutLeaves.
+var ErrNoLeaves = errors.New("merkle: tree doesn't store leaves")
+
+// ErrMalformedProof is returned when either the leaf, the root or any of the
+// proof hashes size doesn't match the output size of the hashing algorithm.
+var ErrMalformedProof = errors.New("merkle: malformed proof")
+
+// ErrMaxDepth is returned when the tree would exceed the WithMaxDepth depth.
+var ErrMaxDepth = errors.New("merkle: max depth exceeded")
+
+// ErrInconsistentProof is returned when a proof built by the tree
+// doesn't verify against its own merkle root.
+var ErrInconsistentProof = errors.New("merkle: inconsistent proof")
+
+// ErrHashSize is returned when the provided hash size doesn't
+// match the output size of the tree hashing algorithm.
+var ErrHashSize = errors.New("merkle: hash size mismatch")
+
 // Tree is a whole merkle tree.
+//
+// A Tree is never mutated once built, but by Insert, Update and Compact, hence it's safe to build
+// proofs concurrently from multiple goroutines, that is, calling Proof,
+// ProofWithIndex, Prove, ProveBundle, Neighbors and Snapshot as well as
+// Graphify-ing its nodes. The inner Nodes of compacted trees are rebuilt
+// just once, by whichever call needs them first, the others waiting for it.
+// The only exception is Verify, which uses the
+// hashing algorithm the tree was built with, and hash.Hash implementations
+// are generally not safe for concurrent use, unless built WithHashLock.
 type Tree struct {
 	// the merkle root Node
 	root *Node
 	// stored for convenience to avoid traversing
 	leaves Nodes
+	// the hashing algorithm and config the tree was built with
+	h hash.Hash
+	c *config
+	// the multiplicity of each leaf, if built WithMultiplicities
+	counts map[string]int
+	// the padding leaves, if padded up to a power of two
+	padding map[*Node]bool
+	// the number of leaves, kept for trees built WithoutLeaves too
+	size int
+	// the name of the hashing algorithm, either the one provided to
+	// NewTreeNamed or the registered one, resolved once when built
+	algo string
+	// the Bloom filter of the leaves, if built WithBloomFilter
+	bloom *bloomFilter
+	// the size commitment, if built WithSizeCommitment
+	sized *Node
+	// rebuilds the inner nodes once, if compacted
+	compacted *sync.Once
 }
 
 // NewTree builds up a new merkle tree with the provided
 // hashing algorithm and set of leaves that have been
 // hashed with the same algorithm.
-func NewTree(h hash.Hash, hl [][]byte) *Tree {
-	// turning leaves into nodes.
-	leaves := byteArrSliceToNodes(hl...)
+// The way the tree is built can be customised with Option(s).
+//
+// The tree keeps using h, hence h must not be shared with other trees
+// used concurrently, either use NewTreeFunc or WithHashLock to do so.
+func NewTree(h hash.Hash, hl [][]byte, opts ...Option) *Tree {
+	return buildLeaves(h, hl, newConfig(opts...))
+}
+
+// buildLeaves builds up the tree out of the provided hashed leaves with
+// the provided config, collapsing, padding and sorting them beforehand.
+func buildLeaves(h hash.Hash, hl [][]byte, c *config) *Tree {
+	var counts map[string]int
+	if c.multiplicities {
+		hl, counts = c.collapse(h, hl)
+	}
+	t := c.padTree(h, byteArrSliceToNodes(hl...))
+	t.counts = counts
+	return t
+}
+
+// padTree pads and sorts the provided leaves and builds up the tree,
+// keeping track of the padding leaves to tell them from the actual ones.
+func (c *config) padTree(h hash.Hash, leaves Nodes) *Tree {
+	n := len(leaves)
+	// turning leaves into nodes, padding them with blinding ones if any.
+	leaves = c.pad(h, leaves)
+	var padding map[*Node]bool
+	if len(leaves) > n && !c.withoutLeaves {
+		padding = make(map[*Node]bool, len(leaves)-n)
+		for _, l := range leaves[n:] {
+			padding[l] = true
+		}
+	}
 	// sorting leaves lexicographically this will come
 	// in handy to efficiently build proofs and find leaves.
-	sort.Sort(leaves)
+	// Some modes keep leaves in the provided order instead.
+	if c.mode.sortsLeaves() {
+		c.sortNodes(leaves)
+	}
+	t := newTree(h, leaves, c)
+	t.padding = padding
+	return t
+}
+
+// unpadded returns the hashed leaves the tree was built from, that is,
+// leaving padding leaves out and repeating collapsed ones by their count.
+func (t Tree) unpadded() [][]byte {
+	if t.counts != nil {
+		hl := make([][]byte, 0, len(t.counts))
+		for l, count := range t.counts {
+			for ; count > 0; count-- {
+				hl = append(hl, []byte(l))
+			}
+		}
+		return hl
+	}
+	hl := make([][]byte, 0, len(t.leaves))
+	for _, l := range t.leaves {
+		if !t.padding[l] {
+			hl = append(hl, l.val)
+		}
+	}
+	return hl
+}
+
+// derived tells whether the config makes the tree leaves out of the hashed
+// leaves rather than using them as is, that is, padding or collapsing them.
+func (c *config) derived() bool {
+	return c.pads() || c.multiplicities
+}
+
+// rebuild builds up the tree all over again out of the provided hashed
+// leaves with its config, same as NewTree does, keeping its algorithm name.
+func (t *Tree) rebuild(hl [][]byte) {
+	algo := t.algo
+	*t = *buildLeaves(t.h, hl, t.c)
+	t.algo = algo
+}
+
+// leafCountWith returns the number of leaves the tree would be made of
+// before padding once the provided hashed leaves are inserted, that is,
+// the number of distinct ones for trees built WithMultiplicities.
+func (t Tree) leafCountWith(hl ...[]byte) int {
+	if t.counts == nil {
+		return len(t.leaves) - len(t.padding) + len(hl)
+	}
+	n := len(t.counts)
+	added := make(map[string]bool, len(hl))
+	for _, l := range hl {
+		if t.counts[string(l)] == 0 && !added[string(l)] {
+			added[string(l)] = true
+			n++
+		}
+	}
+	return n
+}
+
+// NewTreeE builds up a new merkle tree same as NewTree does, but it
+// returns ErrMaxDepth rather than panicking if the tree would
+// exceed the depth set WithMaxDepth, once padded and collapsed.
+func NewTreeE(h hash.Hash, hl [][]byte, opts ...Option) (*Tree, error) {
+	if c := newConfig(opts...); c.exceedsDepth(c.leafCount(hl)) {
+		return nil, ErrMaxDepth
+	}
+	return NewTree(h, hl, opts...), nil
+}
+
+// NewTreeFromPacked builds up a new merkle tree same as NewTree does out
+// of leaves packed as one buffer of concatenated hashSize-byte hashes,
+// as commonly stored by binary formats. It returns ErrHashSize if the
+// length of packed is not a multiple of hashSize and ErrNoLeaves if empty.
+// The leaves share the memory of packed, which must not be modified.
+func NewTreeFromPacked(h hash.Hash, packed []byte, hashSize int, opts ...Option) (*Tree, error) {
+	if hashSize < 1 || len(packed)%hashSize != 0 {
+		return nil, ErrHashSize
+	}
+	if len(packed) == 0 {
+		return nil, ErrNoLeaves
+	}
+	hl := make([][]byte, 0, len(packed)/hashSize)
+	for i := 0; i < len(packed); i += hashSize {
+		hl = append(hl, packed[i:i+hashSize:i+hashSize])
+	}
+	return NewTree(h, hl, opts...), nil
+}
+
+// NewTreeFunc builds up a new merkle tree same as NewTree, getting its
+// own hashing algorithm from newHash, e.g. sha256.New. This is the safe
+// way to build trees concurrently as no hash.Hash is ever shared.
+func NewTreeFunc(newHash func() hash.Hash, hl [][]byte, opts ...Option) *Tree {
+	return NewTree(newHash(), hl, opts...)
+}
+
+// NewTreeNamed builds up a new merkle tree same as NewTree does, naming
+// its hashing algorithm, e.g. "sha256" or an OID, which is then embedded
+// in the JSON encoding of both Bundle and Proof, making them
+// self-describing, and checked by their verifiers, see AlgoName.
+func NewTreeNamed(name string, h hash.Hash, hl [][]byte, opts ...Option) *Tree {
+	t := NewTree(h, hl, opts...)
+	t.algo = name
+	return t
+}
+
+// AlgoName returns the name of the tree hashing algorithm, the one provided
+// to NewTreeNamed or else the one it was registered under when the tree was
+// built, see RegisterHash. It returns an empty string if the algorithm is
+// neither named nor known.
+func (t Tree) AlgoName() string {
+	if t.algo != "" {
+		return t.algo
+	}
+	return hashName(t.h)
+}
+
+// NewTreeSorted builds up a new merkle tree same as NewTree but it trusts
+// the provided leaves to be already sorted in ascending order, skipping
+// the sorting step altogether. This is a considerable speedup for
+// large sets of leaves coming already sorted, e.g. from a database scan.
+//
+// Providing unsorted leaves leads to a broken tree, WithSortedCheck
+// can be used to assert the order while debugging.
+func NewTreeSorted(h hash.Hash, hl [][]byte, opts ...Option) *Tree {
+	c := newConfig(opts...)
+	leaves := byteArrSliceToNodes(hl...)
+	if c.sortedCheck && !sort.SliceIsSorted(leaves, func(i, j int) bool {
+		return c.less(leaves[i].val, leaves[j].val)
+	}) {
+		panic("merkle: leaves are not sorted in ascending order")
+	}
+	return newTree(h, leaves, c)
+}
+
+// newTree builds up the tree from the already sorted leaves.
+func newTree(h hash.Hash, leaves Nodes, c *config) *Tree {
+	if c.exceedsDepth(len(leaves)) {
+		panic(ErrMaxDepth)
+	}
+	defer c.lock()()
+	if c.withoutLeaves && c.oddHandler != nil {
+		// odd handlers work on Nodes, thus they're built
+		// anyway and discarded as soon as the root is computed.
+		root := newNode(buildTree(h, leaves, nil, c, true, 0).val)
+		return (&Tree{root: root, h: h, c: c, size: len(leaves), algo: hashName(h)}).commitSize()
+	}
+	if c.withoutLeaves {
+		// folding leaves straight to the root, no node is kept around.
+		root := newNode(foldLeaves(h, leaves.ToByteArrays(), c))
+		return (&Tree{root: root, h: h, c: c, size: len(leaves), algo: hashName(h)}).commitSize()
+	}
 	// building up tree up to root.
-	root := buildTree(h, leaves)
-	return &Tree{root, leaves}
+	root := buildTree(h, leaves, nil, c, true, 0)
+	t := &Tree{root: root, leaves: leaves, h: h, c: c, size: len(leaves), algo: hashName(h)}
+	if c.bloomRate > 0 && c.bloomRate < 1 {
+		t.bloom = newBloomFilter(len(leaves), c.bloomRate)
+		for _, l := range leaves {
+			t.bloom.add(l.val)
+		}
+	}
+	return t.commitSize()
+}
+
+// commitSize computes the size commitment of trees built WithSizeCommitment,
+// once per root, with the hash lock held. It returns the tree for chaining.
+func (t *Tree) commitSize() *Tree {
+	if t.c.sizeCommitment {
+		t.sized = newNode(commitSize(t.h, t.root.val, t.size))
+	}
+	return t
+}
+
+// publishedRoot returns the root hash the tree publishes, that is,
+// the size commitment for trees built WithSizeCommitment.
+func (t Tree) publishedRoot() []byte {
+	if t.sized != nil {
+		return t.sized.val
+	}
+	return t.root.val
+}
+
+// Merge builds up a new merkle tree with the provided hashing
+// algorithm over the leaves of both a and b, which are left untouched.
+// Being the leaves of both trees already sorted, they're merged
+// in linear time rather than being sorted all over again.
+//
+// Same as NewTree, leaves are treated as a multiset, that is, leaves
+// present in both trees are kept twice rather than being deduplicated.
+// In modes keeping leaves in the provided order, the leaves of b
+// are simply appended to the ones of a.
+// Both trees are expected to be built with the same hashing algorithm
+// and Option(s), the ones of a are used to build the merged tree.
+// Trees whose leaves are padded or collapsed, e.g. WithMultiplicities,
+// are merged out of the leaves they were built from instead, same as
+// NewTree would build them, so that counts add up and padding is redone.
+func Merge(h hash.Hash, a, b *Tree) *Tree {
+	if a.c.derived() || b.c.derived() {
+		return buildLeaves(h, append(a.unpadded(), b.unpadded()...), a.c)
+	}
+	return newTree(h, a.c.mergeLeaves(a.leaves, b.leaves), a.c)
+}
+
+// mergeLeaves merges the provided already sorted leaves into brand new
+// leaves Nodes, appending b to a in modes keeping the provided order.
+func (c *config) mergeLeaves(a, b Nodes) Nodes {
+	leaves := make(Nodes, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) && c.mode.sortsLeaves() {
+		if c.less(b[j].val, a[i].val) {
+			leaves = append(leaves, newNode(b[j].val))
+			j++
+		} else {
+			leaves = append(leaves, newNode(a[i].val))
+			i++
+		}
+	}
+	for ; i < len(a); i++ {
+		leaves = append(leaves, newNode(a[i].val))
+	}
+	for ; j < len(b); j++ {
+		leaves = append(leaves, newNode(b[j].val))
+	}
+	return leaves
 }
 
 // Root returns the root *Node a.k.a merkle root.
+// For trees built WithSizeCommitment it's a detached *Node
+// holding the size commitment rather than the actual root.
 func (t Tree) Root() *Node {
+	// the root exposes its children, which compacted trees rebuild first.
+	t.expand()
+	if t.sized != nil {
+		return t.sized
+	}
 	return t.root
 }
 
-func buildTree(h hash.Hash, n Nodes) *Node {
+// Walk walks the tree nodes in pre-order starting from the root, same as
+// Node.WalkPreOrder does, doing nothing if the tree has no root at all.
+func (t Tree) Walk(fn func(n *Node, depth int)) {
+	if t.root == nil {
+		return
+	}
+	t.expand()
+	t.root.WalkPreOrder(fn)
+}
+
+// RootExcluding returns the merkle root the tree would have without the
+// provided hashed leaves, leaving the tree untouched. Every occurrence of
+// the excluded leaves is left out, leaves not part of the tree are ignored.
+// The root is the one Root returns, that is, the size commitment for trees
+// built WithSizeCommitment, of a tree built out of the remaining leaves
+// with the same Option(s), padding and collapsing them afresh, hence
+// trees built WithBlindingPadding are blinded with new leaves.
+// It returns nil if no leaves are left, as well as for trees built
+// WithoutLeaves.
+func (t Tree) RootExcluding(exclude [][]byte) []byte {
+	if t.c.withoutLeaves {
+		return nil
+	}
+	excluded := make(map[string]bool, len(exclude))
+	for _, e := range exclude {
+		excluded[string(e)] = true
+	}
+	all := t.unpadded()
+	hl := make([][]byte, 0, len(all))
+	for _, l := range all {
+		if !excluded[string(l)] {
+			hl = append(hl, l)
+		}
+	}
+	if len(hl) == 0 {
+		return nil
+	}
+	if len(hl) == len(all) {
+		return t.publishedRoot()
+	}
+	// the tree being thrown away, there's no point in a Bloom filter.
+	c := *t.c
+	c.bloomRate = 0
+	return buildLeaves(t.h, hl, &c).publishedRoot()
+}
+
+// ToNested returns the tree as nested maps from the root down to the
+// leaves, where each node is {"hash": "<hex>", "children": [...]} and
+// leaves have no children. This is easier to feed into html/template,
+// or to marshal, than the Nodes themselves.
+func (t Tree) ToNested() map[string]interface{} {
+	var nest func(n *Node) map[string]interface{}
+	nest = func(n *Node) map[string]interface{} {
+		children := []interface{}{}
+		if n.left != nil {
+			children = append(children, nest(n.left))
+		}
+		if n.right != nil {
+			children = append(children, nest(n.right))
+		}
+		return map[string]interface{}{"hash": n.Hex(), "children": children}
+	}
+	t.expand()
+	return nest(t.root)
+}
+
+// Insert inserts the provided hashed leaf into the tree and rebuilds it.
+// Being the leaves already sorted, the leaf is spliced in at the position
+// found with binary search, which is linear rather than re-sorting leaves.
+// It has no effect on trees built WithoutLeaves as there's nothing to
+// insert the leaf into. In modes keeping leaves in the provided order
+// the leaf is appended instead. For trees built WithMultiplicities the
+// count of the leaf is incremented, replacing its annotated leaf.
+// Padded trees are rebuilt out of their actual leaves plus the new one
+// instead, padding them again same as NewTree does.
+// It returns ErrMaxDepth, leaving the tree untouched, if the tree would
+// exceed the depth set WithMaxDepth.
+// It's not safe for concurrent use.
+func (t *Tree) Insert(hl []byte) error {
+	if t.c.withoutLeaves {
+		return nil
+	}
+	if t.c.exceedsDepth(t.leafCountWith(hl)) {
+		return ErrMaxDepth
+	}
+	if t.c.pads() {
+		t.rebuild(append(t.unpadded(), hl))
+		return nil
+	}
+	if t.counts != nil {
+		return t.recount(nil, hl)
+	}
+	i := len(t.leaves)
+	if t.c.mode.sortsLeaves() {
+		i, _ = t.leafIndex(hl)
+	}
+	t.leaves = append(t.leaves, nil)
+	copy(t.leaves[i+1:], t.leaves[i:])
+	t.leaves[i] = newNode(hl)
+	t.size = len(t.leaves)
+	t.track(hl)
+	defer t.c.lock()()
+	t.root = buildTree(t.h, t.leaves, nil, t.c, true, 0)
+	t.compacted = nil
+	t.commitSize()
+	return nil
+}
+
+// Update replaces the provided old hashed leaf with the new one, rehashing
+// just the O(log n) ancestors of the leaf up to the root, re-evaluating
+// the order of children pairs at each level in modes sorting them.
+//
+// In modes sorting leaves, the whole tree is rebuilt instead whenever the
+// new leaf doesn't sort at the same position the old one was at, as well
+// as for trees built WithSizedCombine, WithOddHandler or WithLevelHasher,
+// whose inner nodes hashes can't be told from their children alone.
+// For trees built WithMultiplicities, old and new are the leaves rather
+// than the annotated ones, one occurrence of old is replaced with new
+// and the tree is rebuilt with their updated counts.
+// Trees built WithBlindingPadding are rebuilt with brand new blinding
+// leaves, same as Insert does, so that roots don't tell them apart.
+// It returns ErrLeafNotFound if old is not part of the tree and
+// ErrNoLeaves for trees built WithoutLeaves.
+// It's not safe for concurrent use.
+func (t *Tree) Update(old, new []byte) error {
+	if t.c.withoutLeaves {
+		return ErrNoLeaves
+	}
+	if t.c.padding > 0 {
+		hl := t.unpadded()
+		for i := range hl {
+			if bytes.Equal(hl[i], old) {
+				hl[i] = new
+				t.rebuild(hl)
+				return nil
+			}
+		}
+		return ErrLeafNotFound
+	}
+	if t.counts != nil {
+		return t.recount(old, new)
+	}
+	i, ok := t.leafIndex(old)
+	if !ok {
+		return ErrLeafNotFound
+	}
+	t.expand()
+	defer t.c.lock()()
+	// the size is the same, the root isn't.
+	defer t.commitSize()
+
+	leaf := t.leaves[i]
+	leaf.val = new
+	// the old leaf can't be removed, it's a false positive
+	// from now on, until the filter is rebuilt.
+	t.track(new)
+	// whether the new leaf sorts at a different position than the old one.
+	moved := t.c.mode.sortsLeaves() &&
+		(i > 0 && t.c.less(new, t.leaves[i-1].val) || i+1 < len(t.leaves) && t.c.less(t.leaves[i+1].val, new))
+	if moved || t.c.sized || t.c.oddHandler != nil || t.c.levelHasher != nil {
+		t.c.sortNodes(t.leaves)
+		t.root = buildTree(t.h, t.leaves, nil, t.c, true, 0)
+		return nil
+	}
+
+	for n := leaf.parent; n != nil; n = n.parent {
+		if !t.c.mode.positional() && t.c.less(n.right.val, n.left.val) {
+			n.left, n.right = n.right, n.left
+		}
+		n.val = t.c.combine(t.h, n.left.val, n.right.val, 0)
+	}
+	return nil
+}
+
+// track adds the provided hashed leaf, which must be part of the leaves
+// already, to the tree Bloom filter, if any. Once full, the filter is
+// rebuilt for twice the number of leaves instead, dropping replaced ones.
+func (t *Tree) track(hl []byte) {
+	switch {
+	case t.bloom == nil:
+	case !t.bloom.full():
+		t.bloom.add(hl)
+	default:
+		t.bloom = newBloomFilter(2*len(t.leaves), t.c.bloomRate)
+		for _, l := range t.leaves {
+			t.bloom.add(l.val)
+		}
+	}
+}
+
+// HashSize returns the output size of the tree hashing algorithm, that is,
+// the size every leaf, inner node and merkle root is expected to be.
+func (t Tree) HashSize() int {
+	return t.h.Size()
+}
+
+// buildTree pairs and hashes the Nodes level by level up to the root.
+// sizes holds the number of leaves under each of the Nodes and
+// it's only tracked when the config combine needs it.
+// sorted tells whether the Nodes are known to be sorted, which holds
+// for the leaves only, in which case pairs are not compared at all.
+// level is the level of the Nodes, starting from 0 at the leaves.
+func buildTree(h hash.Hash, n Nodes, sizes []int, c *config, sorted bool, level int) *Node {
+	if c.sized && sizes == nil {
+		// at the very bottom each leaf commits to itself only.
+		sizes = make([]int, len(n))
+		for i := range sizes {
+			sizes[i] = 1
+		}
+	}
+
+	// the algorithm of the tree unless picked per level.
+	h = c.hasher(h, level)
+
 	// allocating with just enough capacity.
 	// +1 to give space for eventual odd to re-balance
 	ps := make(Nodes, 0, len(n)/2+1)
+	var psizes []int
+	if c.sized {
+		psizes = make([]int, 0, len(n)/2+1)
+	}
 
 	// pairing sorted nodes and making parents hashing pairs.
 	// if an odd number of nodes was provided the last
 	// item will be removed and will be re-used later to re-balance
-	odd := n.IterateSortedPair(func(i, j *Node) {
-		// hashing paired nodes
-		h.Reset()
-		h.Write(i.val)
-		h.Write(j.val)
+	odd := n.IteratePair(func(i, j *Node) {
+		if !sorted && !c.mode.positional() && c.less(j.val, i.val) {
+			// i > j
+			i, j = j, i
+		}
+		size := 0
+		if c.sized {
+			// pairs are iterated in order, thus the
+			// current pair starts at twice its index.
+			k := len(ps) * 2
+			size = sizes[k] + sizes[k+1]
+			psizes = append(psizes, size)
+		}
 		// making parent node from hashed pair
-		p := newParentNode(h.Sum(nil), i, j)
+		p := newParentNode(c.cachedCombine(h, i.val, j.val, size), i, j)
 		// attaching parent node
 		i.parent = p
 		j.parent = p
@@ -78,15 +621,36 @@ func buildTree(h hash.Hash, n Nodes) *Node {
 		ps = append(ps, p)
 	})
 
+	// letting the config odd handler pick the node to carry up, which
+	// commits to the odd only when it's not the odd itself.
+	if odd != nil && c.oddHandler != nil && len(n) > 1 {
+		if p := c.oddHandler(odd, level); p != odd {
+			if p.IsLeaf() {
+				p.left, p.right = odd, odd
+			}
+			odd.parent = p
+			odd = p
+		}
+	} else if odd != nil && c.duplicateOdd && len(n) > 1 {
+		// if there is an odd pairing it with itself
+		// when duplicating rather than promoting it.
+		p := newParentNode(c.cachedCombine(h, odd.val, odd.val, 0), odd, odd)
+		odd.parent = p
+		odd = p
+	}
+
 	// if there is an odd push it back to re-balance
 	if odd != nil {
 		ps = append(ps, odd)
+		if c.sized {
+			psizes = append(psizes, sizes[len(sizes)-1])
+		}
 	}
 
 	// recursively building up tree
 	// until we have only one node (aka merkle root)
 	if len(ps) > 1 {
-		return buildTree(h, ps)
+		return buildTree(h, ps, psizes, c, false, level+1)
 	}
 
 	// merkle root reached
@@ -94,49 +658,477 @@ func buildTree(h hash.Hash, n Nodes) *Node {
 }
 
 // Proof builds and returns the merkle proof for the provided hashed leaf.
+// Levels where the leaf path is promoted are omitted, hence every
+// Node of the proof is a sibling actually combined with.
 func (t Tree) Proof(hl []byte) Nodes {
-	// at first, let's find out whether the leaf actually
-	// exists. Given that the leaves were originally sorted
-	// we can use binary search to efficiently find the leaf.
-	ihl := sort.Search(len(t.leaves), func(i int) bool {
-		cmp := bytes.Compare(t.leaves[i].val, hl)
-		return cmp == 1 || cmp == 0 // t.leaves[i].val >= hl
-	})
+	proof, _, _ := t.ProofWithIndex(hl)
+	return proof
+}
 
-	// checking whether the leaf was actually found, if not
-	// we will just simply return an empty slice of Nodes
-	if ihl >= len(t.leaves) || bytes.Compare(t.leaves[ihl].val, hl) != 0 {
-		return Nodes{}
+// ProofWithIndex builds and returns the merkle proof for the provided
+// hashed leaf alongside the leaf's index within the sorted leaves.
+// If the leaf can't be found an empty proof, -1 and false are returned.
+func (t Tree) ProofWithIndex(hl []byte) (Nodes, int, bool) {
+	ihl, ok := t.findLeaf(hl)
+	if !ok {
+		return Nodes{}, -1, false
 	}
 
 	// allocating just enough capacity leaving
 	// enough space for an eventual odd as well
-	proof := make(Nodes, 0, len(t.leaves)/2)
-	var buildProof func(n *Node)
-	buildProof = func(n *Node) {
-		if n != t.root {
-			proof = append(proof, n.Sibling())
-			buildProof(n.parent)
-		}
+	proof := t.proofAt(ihl, make(Nodes, 0, len(t.leaves)/2))
+
+	return proof, ihl, true
+}
+
+// ProofInto builds the merkle proof for the provided hashed leaf same as
+// Proof does, appending it to buf[:0] and returning it, so that callers
+// can reuse proof buffers across calls rather than allocating new ones.
+// If the leaf can't be found buf[:0] and false are returned.
+func (t Tree) ProofInto(hl []byte, buf Nodes) (Nodes, bool) {
+	i, ok := t.findLeaf(hl)
+	if !ok {
+		return buf[:0], false
 	}
-	buildProof(t.leaves[ihl])
+	return t.proofAt(i, buf[:0]), true
+}
+
+// proofAt appends the siblings of the leaf at index i up to the root to proof.
+func (t Tree) proofAt(i int, proof Nodes) Nodes {
+	return t.nodeProof(t.leaves[i], proof)
+}
 
+// nodeProof appends to proof the siblings of the provided Node up to the root.
+func (t Tree) nodeProof(n *Node, proof Nodes) Nodes {
+	t.expand()
+	for ; n != t.root; n = n.parent {
+		proof = append(proof, n.Sibling())
+	}
 	return proof
 }
 
+// Prove builds and returns the merkle proof for the provided hashed leaf
+// same as Proof does, but it returns ErrHashSize if the leaf size doesn't
+// match the tree HashSize or ErrLeafNotFound if it's not part of the tree.
+// This tells apart a leaf hashed with the wrong algorithm from a missing one.
+// ErrNoLeaves is returned for trees built WithoutLeaves.
+func (t Tree) Prove(hl []byte) (Nodes, error) {
+	if t.c.withoutLeaves {
+		return nil, ErrNoLeaves
+	}
+	if err := t.checkSize(hl); err != nil {
+		return nil, err
+	}
+	proof, _, ok := t.ProofWithIndex(hl)
+	if !ok {
+		return nil, ErrLeafNotFound
+	}
+	return proof, nil
+}
+
+// Verify verifies whether the provided proof for the hashed leaf is valid
+// against the tree merkle root, honouring the Option(s) the tree was
+// built with. It returns ErrHashSize if either the leaf or any of
+// the proof Nodes sizes doesn't match the tree HashSize.
+// It's not safe for concurrent use as it shares the tree hashing
+// algorithm, unless the tree was built WithHashLock.
+func (t Tree) Verify(hl []byte, proof Nodes) (bool, error) {
+	if err := t.checkSize(hl); err != nil {
+		return false, err
+	}
+	if err := t.checkSize(proof.ToByteArrays()...); err != nil {
+		return false, err
+	}
+	i, ok := t.leafIndex(hl)
+	if !ok && t.c.mode.positional() {
+		return false, nil
+	}
+	defer t.c.lock()()
+	return t.c.verifyAt(t.h, hl, t.root.val, proof.ToByteArrays(), i, len(t.leaves)), nil
+}
+
+// checkSize returns ErrHashSize if any of the
+// provided hashes doesn't match the tree HashSize.
+func (t Tree) checkSize(hs ...[]byte) error {
+	size := t.HashSize()
+	for _, h := range hs {
+		if len(h) != size {
+			return ErrHashSize
+		}
+	}
+	return nil
+}
+
+// AssertProofsConsistent builds the proof of every leaf and verifies it
+// against the tree merkle root honouring the tree Option(s), the same way
+// Tree.Verify does. It's a self-test meant for users' tests, giving
+// confidence about trees built with custom Option(s), e.g. WithCombine.
+// The first failure is reported wrapping ErrInconsistentProof, while
+// ErrNoLeaves is returned for trees built WithoutLeaves.
+func (t Tree) AssertProofsConsistent() error {
+	if t.c.withoutLeaves {
+		return ErrNoLeaves
+	}
+	t.expand()
+	defer t.c.lock()()
+	for i, l := range t.leaves {
+		// verifying at i rather than looking the leaf up,
+		// which may be found elsewhere if duplicated.
+		proof := t.proofAt(i, nil).ToByteArrays()
+		if !t.c.verifyAt(t.h, l.val, t.root.val, proof, i, len(t.leaves)) {
+			return fmt.Errorf("%w: leaf %s at index %d", ErrInconsistentProof, l, i)
+		}
+	}
+	return nil
+}
+
+// Neighbors returns the closest leaves bracketing the provided hashed leaf
+// within the sorted leaves, that is, lower is the greatest leaf lesser than
+// hl and upper is the least leaf greater than hl. Either one is nil when
+// hl would be at the extreme on its side. If hl is part of the tree, the
+// found leaf is returned as both lower and upper.
+//
+// This is the building block to confirm the non-membership of a leaf.
+// In modes keeping leaves in the provided order both are nil unless found.
+func (t Tree) Neighbors(hl []byte) (lower, upper *Node) {
+	i, ok := t.leafIndex(hl)
+	if ok {
+		return t.leaves[i], t.leaves[i]
+	}
+	if !t.c.mode.sortsLeaves() {
+		return nil, nil
+	}
+	if i > 0 {
+		lower = t.leaves[i-1]
+	}
+	if i < len(t.leaves) {
+		upper = t.leaves[i]
+	}
+	return
+}
+
+// DirtyAfterUpdate returns the ancestors of the provided hashed leaf, from
+// its parent up to the root, that is, the Nodes whose hashes would change
+// if the leaf were updated. Being every one of them a sibling within the
+// proofs of other leaves, they're exactly the cached proof hashes to
+// invalidate. An empty Nodes is returned if the leaf can't be found.
+func (t Tree) DirtyAfterUpdate(hl []byte) Nodes {
+	i, ok := t.leafIndex(hl)
+	if !ok {
+		return Nodes{}
+	}
+	t.expand()
+	return t.leaves[i].Ancestors()
+}
+
+// CommonAncestorDepth returns the depth of the lowest common ancestor of
+// the provided hashed leaves, the root being at depth 0. The deeper the
+// common ancestor the more their proofs overlap, as the proofs share
+// every sibling above it. A leaf is its own common ancestor with itself.
+// It returns ErrLeafNotFound if either leaf is not part of the tree
+// and ErrNoLeaves for trees built WithoutLeaves.
+func (t Tree) CommonAncestorDepth(a, b []byte) (int, error) {
+	if t.c.withoutLeaves {
+		return 0, ErrNoLeaves
+	}
+	ia, oka := t.leafIndex(a)
+	ib, okb := t.leafIndex(b)
+	if !oka || !okb {
+		return 0, ErrLeafNotFound
+	}
+
+	t.expand()
+	// marking the chain of a up to the root, then
+	// walking up the chain of b to the first marked one.
+	chain := map[*Node]bool{}
+	for n := t.leaves[ia]; n != nil; n = n.parent {
+		chain[n] = true
+	}
+	n := t.leaves[ib]
+	for !chain[n] {
+		n = n.parent
+	}
+	return len(n.Ancestors()), nil
+}
+
+// SwapImpact tells which Nodes would change, and whether the root would,
+// if the provided hashed leaves swapped positions within the tree layout,
+// without rebuilding it. affected lists the ancestors of a, from its parent
+// up to the root, followed by the ancestors of b below the common ancestor.
+//
+// A swap is positional, that is, each leaf takes the place of the other
+// while the layout is kept as is. In modes sorting leaves rebuilding the
+// tree would sort them back, hence a value swap never changes the root
+// of such trees as the set of leaves is the same. Furthermore, pairs
+// being sorted in ModeSorted, swapping siblings changes nothing at all.
+// Nothing changes either if the leaves are equal or any is not found.
+func (t Tree) SwapImpact(a, b []byte) (affected Nodes, rootChanges bool) {
+	ia, oka := t.leafIndex(a)
+	ib, okb := t.leafIndex(b)
+	if !oka || !okb || bytes.Equal(a, b) {
+		return Nodes{}, false
+	}
+	t.expand()
+	la, lb := t.leaves[ia], t.leaves[ib]
+	if !t.c.mode.positional() && la.parent == lb.parent {
+		return Nodes{}, false
+	}
+
+	affected = la.Ancestors()
+	chain := make(map[*Node]bool, len(affected))
+	for _, n := range affected {
+		chain[n] = true
+	}
+	for n := lb.parent; n != nil && !chain[n]; n = n.parent {
+		affected = append(affected, n)
+	}
+	return affected, len(affected) > 0
+}
+
+// Contains tells whether the provided hashed leaf is part of the tree,
+// which for trees built WithBloomFilter is mostly told by the filter alone.
+func (t Tree) Contains(hl []byte) bool {
+	_, ok := t.findLeaf(hl)
+	return ok
+}
+
+// findLeaf finds the index of the provided hashed leaf same as leafIndex
+// does, ruling out leaves the Bloom filter, if any, has never seen first.
+// Unlike leafIndex, the index of leaves not found is meaningless.
+func (t Tree) findLeaf(hl []byte) (int, bool) {
+	if t.bloom != nil && !t.bloom.mayContain(hl) {
+		return -1, false
+	}
+	return t.leafIndex(hl)
+}
+
+// leafIndex finds the index of the provided hashed leaf
+// within the sorted leaves, reporting whether it was found.
+// Leaves are scanned linearly in modes keeping them in the provided order.
+func (t Tree) leafIndex(hl []byte) (int, bool) {
+	if !t.c.mode.sortsLeaves() {
+		for i, l := range t.leaves {
+			if bytes.Equal(l.val, hl) {
+				return i, true
+			}
+		}
+		return len(t.leaves), false
+	}
+	// given that the leaves were originally sorted
+	// we can use binary search to efficiently find the leaf.
+	return t.c.search(len(t.leaves), func(i int) []byte {
+		return t.leaves[i].val
+	}, hl)
+}
+
 // Verify verifies whether the provided proof for leaf is valid.
+//
+// An empty proof is valid only if leaf is the root itself, which is
+// the case of a single leaf tree whose merkle root is the very leaf.
 func Verify(algo hash.Hash, leaf, root []byte, proof [][]byte) bool {
-	for _, h := range proof {
-		// leaf is a left child node
-		i, j := leaf, h
-		if cmp := bytes.Compare(leaf, h); cmp == 1 {
-			// leaf is a right child node
-			i, j = h, leaf
-		}
-		algo.Reset()
-		algo.Write(i)
-		algo.Write(j)
-		leaf = algo.Sum(nil)
-	}
-	return bytes.Compare(leaf, root) == 0
+	return VerifyWith(algo, leaf, root, proof)
+}
+
+// VerifyN verifies whether the provided proof for leaf is valid same as
+// Verify does, but it's stricter as it rejects straight away proofs whose
+// length isn't wantLen. Knowing the expected proof length, e.g. from the
+// tree height and leaf position, defends against padded proofs.
+func VerifyN(algo hash.Hash, leaf, root []byte, proof [][]byte, wantLen int) bool {
+	if len(proof) != wantLen {
+		return false
+	}
+	return Verify(algo, leaf, root, proof)
+}
+
+// VerifyE verifies whether the provided proof for leaf is valid same as
+// Verify does, but it returns ErrMalformedProof if the leaf, the root
+// or any of the proof hashes size doesn't match algo output size.
+// This tells apart a structurally broken proof from an invalid one.
+func VerifyE(algo hash.Hash, leaf, root []byte, proof [][]byte) (bool, error) {
+	if len(leaf) != algo.Size() || len(root) != algo.Size() {
+		return false, ErrMalformedProof
+	}
+	for _, p := range proof {
+		if len(p) != algo.Size() {
+			return false, ErrMalformedProof
+		}
+	}
+	return Verify(algo, leaf, root, proof), nil
+}
+
+// maxProofLen is the length of the proof of the tallest tree, i.e. one of
+// 2^63 leaves, as there can't be more leaves than a slice can hold.
+const maxProofLen = 63
+
+// ValidateProof checks the shape of the provided proof, e.g. as received
+// from an untrusted client, before running the costlier verification,
+// that is, every step is exactly hashSize bytes long and there are no more
+// steps than the tallest tree could have. It returns ErrMalformedProof,
+// wrapped with the reason, if the proof is malformed.
+func ValidateProof(proof [][]byte, hashSize int) error {
+	if len(proof) > maxProofLen {
+		return fmt.Errorf("%w: %d steps exceed the maximum of %d", ErrMalformedProof, len(proof), maxProofLen)
+	}
+	for i, p := range proof {
+		if p == nil {
+			return fmt.Errorf("%w: step %d is nil", ErrMalformedProof, i)
+		}
+		if len(p) != hashSize {
+			return fmt.Errorf("%w: step %d is %d bytes rather than %d", ErrMalformedProof, i, len(p), hashSize)
+		}
+	}
+	return nil
+}
+
+// VerifyStrict verifies whether the provided proof for leaf is valid same
+// as Verify does, enforcing beforehand the invariants of the default sorted
+// construction which are checkable from the proof alone, that is :
+//   - the leaf, the root and every sibling are exactly algo output size long.
+//   - the proof is shorter than 64 siblings, as no tree can be any taller.
+//
+// Whether the leaf and its first sibling are adjacent in sort order can't
+// be checked, as the first sibling may be an inner node whenever the leaf is
+// promoted and any pair of hashes makes a valid sorted pair once ordered.
+// The same holds for leaves paired with an equal sibling, as leaves are a
+// multiset. Thus strictness rejects malformed proofs early rather than
+// making verification any more sound than folding already is.
+func VerifyStrict(algo hash.Hash, leaf, root []byte, proof [][]byte) bool {
+	if ValidateProof(proof, algo.Size()) != nil {
+		return false
+	}
+	ok, err := VerifyE(algo, leaf, root, proof)
+	return ok && err == nil
+}
+
+// ShareAncestor tells whether both the proofs of leafA and leafB go through
+// the provided ancestor hash, that is, whether folding each proof from its
+// leaf reaches ancestor, which proves both leaves are within the subtree of
+// ancestor without knowing the whole tree. Pairs are folded same as Verify does.
+func ShareAncestor(algo hash.Hash, leafA []byte, proofA [][]byte, leafB []byte, proofB [][]byte, ancestor []byte) bool {
+	return reaches(algo, leafA, proofA, ancestor) && reaches(algo, leafB, proofB, ancestor)
+}
+
+// reaches tells whether folding the proof from leaf goes through target.
+func reaches(algo hash.Hash, leaf []byte, proof [][]byte, target []byte) bool {
+	c := newConfig()
+	for _, p := range proof {
+		if bytes.Equal(leaf, target) {
+			return true
+		}
+		l, r := c.order(leaf, p)
+		leaf = c.combine(algo, l, r, 0)
+	}
+	return bytes.Equal(leaf, target)
+}
+
+// VerifyWithDifficulty verifies whether the provided proof for leaf is
+// valid same as Verify does, and that the root has at least
+// leadingZeroBits leading zero bits, i.e. it meets a difficulty target.
+func VerifyWithDifficulty(algo hash.Hash, leaf, root []byte, proof [][]byte, leadingZeroBits int) bool {
+	return leadingZeros(root) >= leadingZeroBits && Verify(algo, leaf, root, proof)
+}
+
+// leadingZeros returns the number of leading zero bits of b.
+func leadingZeros(b []byte) int {
+	n := 0
+	for _, x := range b {
+		n += bits.LeadingZeros8(x)
+		if x != 0 {
+			break
+		}
+	}
+	return n
+}
+
+// VerifyRootHex verifies whether the provided proof for leaf is valid same
+// as Verify does, but it takes the root as an hexadecimal string, e.g. as
+// returned by Node.Hex. An error is returned if rootHex is not valid hex.
+func VerifyRootHex(algo hash.Hash, leaf []byte, rootHex string, proof [][]byte) (bool, error) {
+	root, err := hex.DecodeString(rootHex)
+	if err != nil {
+		return false, err
+	}
+	return Verify(algo, leaf, root, proof), nil
+}
+
+// VerifyReport verifies whether the provided proof for leaf is valid same
+// as Verify does, and if it's not it reports where verification diverged,
+// that is, the hash computed at each step, the computed and expected roots
+// and the number of steps, which helps debugging proof mismatches between
+// clients and servers. The report is empty for valid proofs.
+func VerifyReport(algo hash.Hash, leaf, root []byte, proof [][]byte) (ok bool, report string) {
+	c := newConfig()
+	var b strings.Builder
+	computed := leaf
+	for i, p := range proof {
+		l, r := c.order(computed, p)
+		computed = c.combine(algo, l, r, 0)
+		fmt.Fprintf(&b, "step %d: sibling %x, computed %x\n", i, p, computed)
+	}
+	if bytes.Equal(computed, root) {
+		return true, ""
+	}
+	fmt.Fprintf(&b, "computed root %x, expected root %x, after %d steps", computed, root, len(proof))
+	return false, b.String()
+}
+
+// VerifyWith verifies whether the provided proof for leaf is valid
+// for a tree built with the provided Option(s).
+//
+// Note that proofs for trees built WithSizedCombine can't be verified
+// as subtree sizes can't be inferred from the proof alone, likewise
+// proofs for trees built in positional modes need VerifyMode. Nor can the
+// level of each step, hence proofs for trees built WithLevelHasher are
+// rejected and need VerifyLevelHashed.
+func VerifyWith(algo hash.Hash, leaf, root []byte, proof [][]byte, opts ...Option) bool {
+	c := newConfig(opts...)
+	if c.levelHasher != nil {
+		return false
+	}
+	if len(proof) == 0 {
+		// degenerate proof of a single leaf tree.
+		return bytes.Equal(leaf, root)
+	}
+	return bytes.Equal(c.reconstruct(algo, leaf, proof), root)
+}
+
+// VerifyLazy verifies whether the proof for leaf is valid same as Verify
+// does, but rather than requiring the whole proof upfront, each of its
+// depth siblings is supplied on demand by fetch, from the leaf level 0 up,
+// e.g. streaming them from remote storage. It fails fast returning the
+// error of fetch, wrapped with the level, or ErrMalformedProof, wrapped
+// with the reason, as soon as a sibling size doesn't match algo output
+// size, without fetching any further sibling.
+func VerifyLazy(algo hash.Hash, leaf, root []byte, depth int, fetch func(level int) ([]byte, error)) (bool, error) {
+	if depth < 0 || depth > maxProofLen {
+		return false, fmt.Errorf("%w: depth %d out of range", ErrMalformedProof, depth)
+	}
+	c := newConfig()
+	for level := 0; level < depth; level++ {
+		p, err := fetch(level)
+		if err != nil {
+			return false, fmt.Errorf("merkle: fetching sibling at level %d: %w", level, err)
+		}
+		if len(p) != algo.Size() {
+			return false, fmt.Errorf("%w: sibling at level %d is %d bytes rather than %d", ErrMalformedProof, level, len(p), algo.Size())
+		}
+		l, r := c.order(leaf, p)
+		leaf = c.combine(algo, l, r, 0)
+	}
+	return bytes.Equal(leaf, root), nil
+}
+
+// VerifyAny verifies the provided proof for leaf against multiple
+// candidate roots, returning the index of the first matching root.
+// If the proof is valid for none of them -1 and false are returned.
+func VerifyAny(algo hash.Hash, leaf []byte, roots [][]byte, proof [][]byte) (int, bool) {
+	// the implied root is the same regardless
+	// of the candidate, thus computing it once.
+	root := newConfig().reconstruct(algo, leaf, proof)
+	for i, r := range roots {
+		if bytes.Equal(root, r) {
+			return i, true
+		}
+	}
+	return -1, false
 }
@@ -1,9 +1,19 @@
 package merkle
 
 import (
+	"bytes"
+	"crypto/hmac"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"hash"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -195,3 +205,1138 @@ func TestVerify(t *testing.T) {
 		}
 	})
 }
+
+func TestTree_ProofWithIndex(t *testing.T) {
+	t.Run("With Non Existent Leaf", func(t *testing.T) {
+		t.Run("Should Return Empty Proof And Not Found", func(t *testing.T) {
+			proof, i, ok := evenLeavesTree.ProofWithIndex([]byte("foo"))
+			if len(proof) > 0 || i != -1 || ok {
+				t.Errorf("expected empty proof, index -1 and not found, got %d, %d, %t", len(proof), i, ok)
+			}
+		})
+	})
+	t.Run("With Odd Leaves", func(t *testing.T) {
+		for expIndex, leaf := range oddLeavesTree.leaves {
+			t.Run("Should Return Sorted Index For Leaf "+leaf.Hex(), func(t *testing.T) {
+				proof, i, ok := oddLeavesTree.ProofWithIndex(leaf.val)
+				if !ok {
+					t.Fatalf("expected leaf to be found")
+				}
+				if i != expIndex {
+					t.Errorf("expected index to be %d, got %d", expIndex, i)
+				}
+				expProof := oddLeavesTreeProofs[leaf.Hex()]
+				if len(expProof) != len(proof) {
+					t.Errorf("expected length of proof to be %d, got %d", len(expProof), len(proof))
+				}
+			})
+		}
+	})
+}
+
+func TestVerifyAny(t *testing.T) {
+	roots := [][]byte{evenLeavesTree.root.val, oddLeavesTree.root.val}
+	t.Run("Should Return Index Of Matching Root", func(t *testing.T) {
+		for leaf, proof := range oddLeavesTreeProofs {
+			leafb, _ := hex.DecodeString(leaf)
+			i, ok := VerifyAny(algo, leafb, roots, hexStringsToByteArrays(proof...))
+			if !ok || i != 1 {
+				t.Errorf("expected proof for %s to be valid for root at index 1, got %d, %t", leaf, i, ok)
+			}
+		}
+	})
+	t.Run("Should Return Not Found With No Matching Root", func(t *testing.T) {
+		for leaf, proof := range oddLeavesTreeProofs {
+			leafb, _ := hex.DecodeString(leaf)
+			i, ok := VerifyAny(algo, leafb, roots[:1], hexStringsToByteArrays(proof...))
+			if ok || i != -1 {
+				t.Errorf("expected proof for %s to be valid for no root, got %d, %t", leaf, i, ok)
+			}
+		}
+	})
+}
+
+func TestNewTreeSorted(t *testing.T) {
+	t.Run("Should Return Expected Merkle Root", func(t *testing.T) {
+		tree := NewTreeSorted(algo, oddLeavesTree.leaves.ToByteArrays())
+		if exp, act := oddLeavesTree.Root().String(), tree.Root().String(); act != exp {
+			t.Errorf("expected merkle root should have been %s, got %s", exp, act)
+		}
+	})
+	t.Run("With Sorted Check", func(t *testing.T) {
+		t.Run("Should Not Panic With Sorted Leaves", func(t *testing.T) {
+			NewTreeSorted(algo, oddLeavesTree.leaves.ToByteArrays(), WithSortedCheck())
+		})
+		t.Run("Should Panic With Unsorted Leaves", func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected to panic")
+				}
+			}()
+			NewTreeSorted(algo, hashStringSlice(algo, "a", "b", "c"), WithSortedCheck())
+		})
+	})
+}
+
+func TestMerge(t *testing.T) {
+	a := NewTree(algo, hashStringSlice(algo, "a", "c", "e"))
+	b := NewTree(algo, hashStringSlice(algo, "b", "d"))
+
+	t.Run("Should Return Same Root As A Single Tree", func(t *testing.T) {
+		exp := oddLeavesTree.Root().String()
+		if act := Merge(algo, a, b).Root().String(); act != exp {
+			t.Errorf("expected merkle root should have been %s, got %s", exp, act)
+		}
+	})
+
+	t.Run("Should Leave Merged Trees Untouched", func(t *testing.T) {
+		exp := NewTree(algo, hashStringSlice(algo, "a", "c", "e")).Root().String()
+		Merge(algo, a, b)
+		if act := a.Root().String(); act != exp {
+			t.Errorf("expected merkle root should have been %s, got %s", exp, act)
+		}
+		for _, l := range a.leaves {
+			if !Verify(algo, l.val, a.Root().Bytes(), a.Proof(l.val).ToByteArrays()) {
+				t.Errorf("proof for leaf %s should have been valid", l)
+			}
+		}
+	})
+
+	t.Run("Should Keep Leaves Present In Both Trees", func(t *testing.T) {
+		merged := Merge(algo, a, a)
+		if exp, act := 6, len(merged.leaves); act != exp {
+			t.Errorf("expected %d leaves, got %d", exp, act)
+		}
+		exp := NewTree(algo, hashStringSlice(algo, "a", "c", "e", "a", "c", "e")).Root().String()
+		if act := merged.Root().String(); act != exp {
+			t.Errorf("expected merkle root should have been %s, got %s", exp, act)
+		}
+	})
+
+	t.Run("Should Add Up Multiplicities", func(t *testing.T) {
+		a := NewTree(algo, hashStringSlice(algo, "a", "c", "a"), WithMultiplicities())
+		b := NewTree(algo, hashStringSlice(algo, "a", "b"), WithMultiplicities())
+		merged := Merge(algo, a, b)
+		exp := NewTree(algo, hashStringSlice(algo, "a", "c", "a", "a", "b"), WithMultiplicities())
+		if act := merged.Root().String(); act != exp.Root().String() {
+			t.Errorf("expected merkle root should have been %s, got %s", exp.Root(), act)
+		}
+		if _, count, err := merged.ProveMultiplicity(hashStringSlice(algo, "a")[0]); err != nil || count != 3 {
+			t.Errorf("expected a count of 3, got %d, %v", count, err)
+		}
+	})
+
+	t.Run("Should Pad Merged Leaves Once", func(t *testing.T) {
+		hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+		for name, opt := range map[string]Option{
+			"Empty Padding":    WithEmptyHashPadding(),
+			"Blinding Padding": WithBlindingPadding(2, nil),
+		} {
+			merged := Merge(algo, NewTree(algo, hl[:3], opt), NewTree(algo, hl[3:], opt))
+			if len(merged.leaves) != 8 || len(merged.padding) != 3 {
+				t.Errorf("%s: expected 8 leaves, 3 of which padding, got %d and %d", name, len(merged.leaves), len(merged.padding))
+			}
+			for _, l := range hl {
+				if !Verify(algo, l, merged.Root().Bytes(), merged.Proof(l).ToByteArrays()) {
+					t.Errorf("%s: proof for leaf %x should have been valid", name, l)
+				}
+			}
+		}
+		exp := NewTree(algo, hl, WithEmptyHashPadding())
+		merged := Merge(algo, NewTree(algo, hl[:3], WithEmptyHashPadding()), NewTree(algo, hl[3:], WithEmptyHashPadding()))
+		if act := merged.Root().String(); act != exp.Root().String() {
+			t.Errorf("expected merkle root should have been %s, got %s", exp.Root(), act)
+		}
+	})
+}
+
+func TestTree_HashSize(t *testing.T) {
+	if exp, act := sha256.Size, oddLeavesTree.HashSize(); act != exp {
+		t.Errorf("expected hash size to be %d, got %d", exp, act)
+	}
+}
+
+func TestTree_Prove(t *testing.T) {
+	t.Run("With Wrong Size Leaf Should Return ErrHashSize", func(t *testing.T) {
+		if _, err := oddLeavesTree.Prove(hashStringSlice(sha512.New(), "a")[0]); err != ErrHashSize {
+			t.Errorf("expected ErrHashSize, got %v", err)
+		}
+	})
+	t.Run("With Non Existent Leaf Should Return ErrLeafNotFound", func(t *testing.T) {
+		if _, err := oddLeavesTree.Prove(hashStringSlice(algo, "f")[0]); err != ErrLeafNotFound {
+			t.Errorf("expected ErrLeafNotFound, got %v", err)
+		}
+	})
+	t.Run("Should Return Expected Proof", func(t *testing.T) {
+		for leaf, expProof := range oddLeavesTreeProofs {
+			leafb, _ := hex.DecodeString(leaf)
+			proof, err := oddLeavesTree.Prove(leafb)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if len(proof) != len(expProof) {
+				t.Errorf("expected length of proof to be %d, got %d", len(expProof), len(proof))
+			}
+		}
+	})
+}
+
+func TestTree_Verify(t *testing.T) {
+	leaf := oddLeavesTree.leaves[0].val
+	proof := oddLeavesTree.Proof(leaf)
+
+	t.Run("Should Be Verified", func(t *testing.T) {
+		if ok, err := oddLeavesTree.Verify(leaf, proof); !ok || err != nil {
+			t.Errorf("proof should have been valid, got %t, %v", ok, err)
+		}
+	})
+	t.Run("Should Not Be Verified Against Another Tree", func(t *testing.T) {
+		if ok, err := evenLeavesTree.Verify(leaf, proof); ok || err != nil {
+			t.Errorf("proof should have been invalid, got %t, %v", ok, err)
+		}
+	})
+	t.Run("With Wrong Size Leaf Should Return ErrHashSize", func(t *testing.T) {
+		if _, err := oddLeavesTree.Verify(leaf[1:], proof); err != ErrHashSize {
+			t.Errorf("expected ErrHashSize, got %v", err)
+		}
+	})
+	t.Run("With Wrong Size Proof Should Return ErrHashSize", func(t *testing.T) {
+		bad := append(Nodes{newNode([]byte("foo"))}, proof[1:]...)
+		if _, err := oddLeavesTree.Verify(leaf, bad); err != ErrHashSize {
+			t.Errorf("expected ErrHashSize, got %v", err)
+		}
+	})
+}
+
+func BenchmarkBuildTree(b *testing.B) {
+	leaves := make(Nodes, 1<<16)
+	for i := range leaves {
+		leaves[i] = newNode(hashStringSlice(sha256.New(), strconv.Itoa(i))[0])
+	}
+	c := newConfig()
+	c.sortNodes(leaves)
+
+	b.Run("Sorted Leaves Fast Path", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			buildTree(sha256.New(), leaves, nil, c, true, 0)
+		}
+	})
+
+	b.Run("Compare Every Pair", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			buildTree(sha256.New(), leaves, nil, c, false, 0)
+		}
+	})
+}
+
+func BenchmarkTree_Update(b *testing.B) {
+	hl := make([][]byte, 1<<16)
+	for i := range hl {
+		hl[i] = hashStringSlice(sha256.New(), strconv.Itoa(i))[0]
+	}
+	tree := NewTree(sha256.New(), hl)
+	// the leaf hash keeps sorting at the same position.
+	leaf := tree.leaves[len(hl)/2].val
+	next := append(append([]byte{}, leaf[:len(leaf)-1]...), leaf[len(leaf)-1]^1)
+	if tree.c.less(tree.leaves[len(hl)/2+1].val, next) {
+		b.Fatalf("expected updated leaf to keep its position")
+	}
+
+	b.Run("Update Root Path", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tree.Update(leaf, next)
+			leaf, next = next, leaf
+		}
+	})
+
+	b.Run("Rebuild Whole Tree", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			NewTree(sha256.New(), hl)
+		}
+	})
+}
+
+func TestTree_Update(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e", "f", "g")
+	for _, m := range []Mode{ModeSorted, ModeUnsorted, ModeOrdered, ModeBitcoin, ModeRFC6962} {
+		for i := range hl {
+			for _, with := range []string{"h", "cc"} {
+				tree := NewTree(algo, hl, WithMode(m))
+				repl := hashStringSlice(algo, with)[0]
+				if err := tree.Update(hl[i], repl); err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				exp := make([][]byte, len(hl))
+				copy(exp, hl)
+				exp[i] = repl
+				expTree := NewTree(algo, exp, WithMode(m))
+				if tree.Root().String() != expTree.Root().String() {
+					t.Errorf("%s updating leaf %d with %s, expected merkle root should have been %s, got %s", m, i, with, expTree.Root(), tree.Root())
+				}
+				if _, _, ok := tree.ProofWithIndex(hl[i]); ok {
+					t.Errorf("expected old leaf not to be found anymore")
+				}
+				proof, j, _ := tree.ProofWithIndex(repl)
+				if !VerifyMode(algo, m, repl, tree.Root().Bytes(), proof.ToByteArrays(), j, len(hl)) {
+					t.Errorf("%s proof for updated leaf should have been valid", m)
+				}
+			}
+		}
+	}
+
+	t.Run("Should Return ErrLeafNotFound", func(t *testing.T) {
+		tree := NewTree(algo, hl)
+		if err := tree.Update(hashStringSlice(algo, "z")[0], hl[0]); err != ErrLeafNotFound {
+			t.Errorf("expected ErrLeafNotFound, got %v", err)
+		}
+	})
+}
+
+func TestTree_RootExcluding(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+
+	t.Run("Should Return Root Without Excluded Leaves", func(t *testing.T) {
+		exp := evenLeavesTree.Root().Bytes()
+		if act := oddLeavesTree.RootExcluding([][]byte{hl[4], hashStringSlice(algo, "f")[0]}); !bytes.Equal(act, exp) {
+			t.Errorf("expected merkle root should have been %x, got %x", exp, act)
+		}
+		if oddLeavesTree.Root().String() == evenLeavesTree.Root().String() {
+			t.Errorf("expected tree to be left untouched")
+		}
+	})
+
+	t.Run("Should Return Nil Excluding Every Leaf", func(t *testing.T) {
+		if act := oddLeavesTree.RootExcluding(hl); act != nil {
+			t.Errorf("expected nil merkle root, got %x", act)
+		}
+	})
+
+	odd := WithOddHandler(func(odd *Node, _ int) *Node {
+		return NodesFromBytes(combine(algo, odd.Bytes(), odd.Bytes()))[0]
+	})
+	dup := append(hashStringSlice(algo, "a"), hl...)
+	for name, opts := range map[string][]Option{
+		"Default":          nil,
+		"RFC6962":          {WithMode(ModeRFC6962)},
+		"Bitcoin":          {WithMode(ModeBitcoin)},
+		"Empty Padding":    {WithEmptyHashPadding()},
+		"Blinding Padding": {WithBlindingPadding(16, nil)},
+		"Size Commitment":  {WithSizeCommitment()},
+		"Multiplicities":   {WithMultiplicities()},
+		"Odd Handler":      {odd},
+		"Bloom Filter":     {WithBloomFilter(0.01)},
+	} {
+		t.Run("Should Return Root Excluding Nothing With "+name, func(t *testing.T) {
+			tree := NewTree(algo, dup, opts...)
+			if exp, act := tree.Root().Bytes(), tree.RootExcluding(nil); !bytes.Equal(act, exp) {
+				t.Errorf("expected merkle root should have been %x, got %x", exp, act)
+			}
+			if name == "Blinding Padding" {
+				return
+			}
+			exp := NewTree(algo, dup[2:], opts...).Root().Bytes()
+			if act := tree.RootExcluding(dup[:2]); !bytes.Equal(act, exp) {
+				t.Errorf("expected merkle root without leaves should have been %x, got %x", exp, act)
+			}
+		})
+	}
+}
+
+func TestTree_Walk(t *testing.T) {
+	t.Run("Should Walk Every Node", func(t *testing.T) {
+		count := 0
+		oddLeavesTree.Walk(func(n *Node, depth int) {
+			count++
+		})
+		if count != 9 {
+			t.Errorf("expected 9 nodes to be walked, got %d", count)
+		}
+	})
+
+	t.Run("Should Do Nothing Without Root", func(t *testing.T) {
+		Tree{}.Walk(func(n *Node, depth int) {
+			t.Errorf("expected no node to be walked")
+		})
+	})
+}
+
+func TestTree_ToNested(t *testing.T) {
+	nested := oddLeavesTree.ToNested()
+
+	t.Run("Should Return Root Hash", func(t *testing.T) {
+		if exp, act := oddLeavesTree.Root().Hex(), nested["hash"]; act != exp {
+			t.Errorf("expected hash to be %s, got %v", exp, act)
+		}
+	})
+
+	t.Run("Should Nest Children Down To Leaves", func(t *testing.T) {
+		// children are laid out as their hashes are sorted, thus
+		// the leftmost leaf is 3e23.. rather than the least one.
+		n := nested
+		for depth := 0; depth < 3; depth++ {
+			children := n["children"].([]interface{})
+			if len(children) != 2 {
+				t.Fatalf("expected 2 children at depth %d, got %d", depth, len(children))
+			}
+			n = children[0].(map[string]interface{})
+		}
+		if exp, act := oddLeavesTree.leaves[2].Hex(), n["hash"]; act != exp {
+			t.Errorf("expected leaf hash to be %s, got %v", exp, act)
+		}
+		if children := n["children"].([]interface{}); len(children) != 0 {
+			t.Errorf("expected leaf to have no children, got %d", len(children))
+		}
+	})
+}
+
+func TestTree_AssertProofsConsistent(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e", "a")
+	for _, m := range []Mode{ModeSorted, ModeUnsorted, ModeOrdered, ModeBitcoin, ModeRFC6962} {
+		t.Run("Should Be Consistent In "+m.String()+" Mode", func(t *testing.T) {
+			if err := NewTree(algo, hl, WithMode(m)).AssertProofsConsistent(); err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+
+	t.Run("Should Return ErrInconsistentProof", func(t *testing.T) {
+		// rehashing odd nodes on their own, which Verify can't tell.
+		tree := NewTree(algo, hl[:5], WithOddHandler(func(odd *Node, _ int) *Node {
+			return NodesFromBytes(rehash(algo, odd.Bytes()))[0]
+		}))
+		if err := tree.AssertProofsConsistent(); !errors.Is(err, ErrInconsistentProof) {
+			t.Errorf("expected ErrInconsistentProof, got %v", err)
+		}
+	})
+}
+
+func TestTree_Neighbors(t *testing.T) {
+	// leaves are sorted as : 18ac.., 2e7d.., 3e23.., 3f79.., ca97..
+	leaves := oddLeavesTree.leaves
+	for name, tc := range map[string]struct {
+		hl           []byte
+		lower, upper *Node
+	}{
+		"Should Return Found Leaf As Both": {leaves[2].val, leaves[2], leaves[2]},
+		"Should Return Bracketing Leaves":  {hexStringsToByteArrays("3f00")[0], leaves[2], leaves[3]},
+		"Should Return nil Lower":          {hexStringsToByteArrays("00")[0], nil, leaves[0]},
+		"Should Return nil Upper":          {hexStringsToByteArrays("ff")[0], leaves[4], nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			lower, upper := oddLeavesTree.Neighbors(tc.hl)
+			if lower != tc.lower || upper != tc.upper {
+				t.Errorf("expected neighbors to be %v and %v, got %v and %v", tc.lower, tc.upper, lower, upper)
+			}
+		})
+	}
+}
+
+func TestTree_ProofInto(t *testing.T) {
+	buf := make(Nodes, 0, 8)
+
+	t.Run("Should Reuse Buffer", func(t *testing.T) {
+		for leaf, expProof := range oddLeavesTreeProofs {
+			leafb, _ := hex.DecodeString(leaf)
+			proof, ok := oddLeavesTree.ProofInto(leafb, buf)
+			if !ok {
+				t.Fatalf("expected leaf %s to be found", leaf)
+			}
+			if &proof[:1][0] != &buf[:1][0] {
+				t.Errorf("expected proof to be appended to the provided buffer")
+			}
+			if exp, act := expProof, proof.ToHexStrings(); !reflect.DeepEqual(exp, act) {
+				t.Errorf("expected proof to be %v, got %v", exp, act)
+			}
+		}
+	})
+
+	t.Run("Should Return False For Non Existent Leaf", func(t *testing.T) {
+		if proof, ok := oddLeavesTree.ProofInto(hashStringSlice(algo, "f")[0], buf); ok || len(proof) != 0 {
+			t.Errorf("expected empty proof and false, got %d, %t", len(proof), ok)
+		}
+	})
+}
+
+func BenchmarkTree_ProofInto(b *testing.B) {
+	leaf := oddLeavesTree.leaves[0].val
+	b.Run("Proof", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			oddLeavesTree.Proof(leaf)
+		}
+	})
+
+	b.Run("ProofInto", func(b *testing.B) {
+		b.ReportAllocs()
+		buf := make(Nodes, 0, 8)
+		for i := 0; i < b.N; i++ {
+			buf, _ = oddLeavesTree.ProofInto(leaf, buf)
+		}
+	})
+}
+
+func TestTree_Proof_PromotedLevels(t *testing.T) {
+	// "a" (ca97..) is the greatest leaf, thus it's
+	// promoted twice before being combined with the root's left child.
+	leaf := hashStringSlice(algo, "a")[0]
+	proof := oddLeavesTree.Proof(leaf)
+
+	t.Run("Should Omit Promoted Levels", func(t *testing.T) {
+		if len(proof) != 1 {
+			t.Errorf("expected length of proof to be 1, got %d", len(proof))
+		}
+		for i, n := range proof {
+			if n == nil {
+				t.Errorf("unexpected placeholder at index %d", i)
+			}
+		}
+	})
+
+	t.Run("Should Be Verified", func(t *testing.T) {
+		if !Verify(algo, leaf, oddLeavesTree.Root().Bytes(), proof.ToByteArrays()) {
+			t.Errorf("proof should have been valid")
+		}
+	})
+}
+
+func TestTree_Proof_PromotedLeaf(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e", "f", "g", "h", "i")
+	c := newConfig()
+	// the greatest leaf is the odd one, whose expected proof is
+	// the roots of the ranges of leaves it's combined with.
+	for n, ranges := range map[int][][2]int{
+		3: {{0, 2}},
+		5: {{0, 4}},
+		7: {{4, 6}, {0, 4}},
+		9: {{0, 8}},
+	} {
+		tree := NewTree(algo, hl[:n])
+		sorted := tree.leaves.ToByteArrays()
+		leaf := sorted[n-1]
+		proof := tree.Proof(leaf)
+
+		t.Run("Should Return Combined Siblings Only Of "+strconv.Itoa(n)+" Leaves", func(t *testing.T) {
+			if len(proof) != len(ranges) {
+				t.Fatalf("expected length of proof to be %d, got %d", len(ranges), len(proof))
+			}
+			for i, r := range ranges {
+				if proof[i] == nil {
+					t.Fatalf("unexpected nil sibling at index %d", i)
+				}
+				if exp := foldLeaves(algo, sorted[r[0]:r[1]], c); !bytes.Equal(proof[i].val, exp) {
+					t.Errorf("expected node at index %d to be %x, got %s", i, exp, proof[i])
+				}
+			}
+		})
+
+		t.Run("Should Be Verified Of "+strconv.Itoa(n)+" Leaves", func(t *testing.T) {
+			if !Verify(algo, leaf, tree.Root().Bytes(), proof.ToByteArrays()) {
+				t.Errorf("proof should have been valid")
+			}
+		})
+	}
+}
+
+func TestVerifyN(t *testing.T) {
+	for leaf, proof := range oddLeavesTreeProofs {
+		leafb, _ := hex.DecodeString(leaf)
+		proofb := hexStringsToByteArrays(proof...)
+		t.Run("Should Be Verified With Expected Length For "+leaf, func(t *testing.T) {
+			if !VerifyN(algo, leafb, oddLeavesTree.root.val, proofb, len(proof)) {
+				t.Errorf("proof should have been valid")
+			}
+		})
+		t.Run("Should Not Be Verified With Unexpected Length For "+leaf, func(t *testing.T) {
+			if VerifyN(algo, leafb, oddLeavesTree.root.val, proofb, len(proof)+1) {
+				t.Errorf("proof should have been invalid")
+			}
+		})
+	}
+}
+
+func TestNewTreeFunc_Hashers(t *testing.T) {
+	// keyed and non 32 bytes hashers, standing in for BLAKE3 keyed mode
+	// and extensible output configured to a different length.
+	key := []byte("key")
+	for name, newHash := range map[string]func() hash.Hash{
+		"Keyed":         func() hash.Hash { return hmac.New(sha256.New, key) },
+		"48 Bytes Long": sha512.New384,
+	} {
+		t.Run("Should Build And Verify With "+name+" Hasher", func(t *testing.T) {
+			h := newHash()
+			hl := hashStringSlice(h, "a", "b", "c", "d", "e")
+			tree := NewTreeFunc(newHash, hl)
+			for _, l := range hl {
+				ok, err := VerifyE(newHash(), l, tree.Root().Bytes(), tree.Proof(l).ToByteArrays())
+				if !ok || err != nil {
+					t.Errorf("proof for leaf %x should have been valid, got %v", l, err)
+				}
+			}
+			if Verify(sha256.New(), hl[0], tree.Root().Bytes(), tree.Proof(hl[0]).ToByteArrays()) {
+				t.Errorf("proof should have been invalid with another hasher")
+			}
+		})
+	}
+}
+
+func TestTree_SwapImpact(t *testing.T) {
+	// leaves laid out as : 3e23.., 3f79.., 18ac.., 2e7d.., ca97..
+	leaves := oddLeavesTree.Root().Leaves()
+
+	t.Run("Should Affect Both Chains Up To Root", func(t *testing.T) {
+		affected, rootChanges := oddLeavesTree.SwapImpact(leaves[0].val, leaves[4].val)
+		if !rootChanges || len(affected) != 3 {
+			t.Fatalf("expected root and 3 nodes to change, got %t and %d", rootChanges, len(affected))
+		}
+		if affected[0] != leaves[0].parent || affected[2] != oddLeavesTree.Root() {
+			t.Errorf("expected ancestors of the first leaf first")
+		}
+	})
+
+	t.Run("Should List Common Ancestors Once", func(t *testing.T) {
+		affected, _ := oddLeavesTree.SwapImpact(leaves[0].val, leaves[2].val)
+		if len(affected) != 4 || affected[3] != leaves[2].parent {
+			t.Errorf("expected 4 nodes to change, got %d", len(affected))
+		}
+	})
+
+	t.Run("Should Not Change Anything Swapping Sorted Siblings", func(t *testing.T) {
+		if affected, rootChanges := oddLeavesTree.SwapImpact(leaves[0].val, leaves[1].val); rootChanges || len(affected) != 0 {
+			t.Errorf("expected nothing to change, got %d nodes", len(affected))
+		}
+	})
+
+	t.Run("Should Change Positional Siblings Parent", func(t *testing.T) {
+		hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+		tree := NewTree(algo, hl, WithMode(ModeOrdered))
+		affected, rootChanges := tree.SwapImpact(hl[0], hl[1])
+		if !rootChanges || len(affected) != 3 {
+			t.Errorf("expected root and 3 nodes to change, got %t and %d", rootChanges, len(affected))
+		}
+	})
+
+	t.Run("Should Not Change Anything For Missing Leaf", func(t *testing.T) {
+		if _, rootChanges := oddLeavesTree.SwapImpact(leaves[0].val, []byte("foo")); rootChanges {
+			t.Errorf("expected nothing to change")
+		}
+	})
+}
+
+func TestTree_AlgoName(t *testing.T) {
+	t.Run("Should Return Provided Name", func(t *testing.T) {
+		tree := NewTreeNamed("sha-256", algo, hashStringSlice(algo, "a", "b"))
+		if tree.AlgoName() != "sha-256" {
+			t.Errorf("expected sha-256, got %q", tree.AlgoName())
+		}
+	})
+
+	t.Run("Should Fall Back To Registered Name", func(t *testing.T) {
+		if oddLeavesTree.AlgoName() != "sha256" {
+			t.Errorf("expected sha256, got %q", oddLeavesTree.AlgoName())
+		}
+	})
+}
+
+func TestVerifyReport(t *testing.T) {
+	leaf := oddLeavesTree.leaves[0].val
+	root := oddLeavesTree.Root().Bytes()
+	proof := oddLeavesTree.Proof(leaf).ToByteArrays()
+
+	t.Run("Should Be Verified With Empty Report", func(t *testing.T) {
+		if ok, report := VerifyReport(algo, leaf, root, proof); !ok || report != "" {
+			t.Errorf("proof should have been valid without report, got %t, %q", ok, report)
+		}
+	})
+
+	t.Run("Should Report Computed And Expected Roots", func(t *testing.T) {
+		ok, report := VerifyReport(algo, leaf, evenLeavesTree.Root().Bytes(), proof)
+		if ok {
+			t.Fatalf("proof should have been invalid")
+		}
+		exp := fmt.Sprintf("computed root %x, expected root %x, after 3 steps", root, evenLeavesTree.Root().Bytes())
+		if !strings.HasSuffix(report, exp) {
+			t.Errorf("expected report to end with %q, got %q", exp, report)
+		}
+		if n := strings.Count(report, "\n"); n != 3 {
+			t.Errorf("expected a line per step, got %d", n)
+		}
+	})
+}
+
+func TestValidateProof(t *testing.T) {
+	proof := oddLeavesTree.Proof(oddLeavesTree.leaves[0].val).ToByteArrays()
+
+	t.Run("Should Accept Well Formed Proof", func(t *testing.T) {
+		for _, p := range [][][]byte{proof, nil} {
+			if err := ValidateProof(p, algo.Size()); err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+		}
+	})
+
+	for name, p := range map[string][][]byte{
+		"Nil Step":       {proof[0], nil},
+		"Truncated Step": {proof[0], proof[1][:16]},
+		"Too Many Steps": make([][]byte, 64),
+	} {
+		t.Run("With "+name+" Should Return ErrMalformedProof", func(t *testing.T) {
+			if err := ValidateProof(p, algo.Size()); !errors.Is(err, ErrMalformedProof) {
+				t.Errorf("expected ErrMalformedProof, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyLazy(t *testing.T) {
+	leaf := oddLeavesTree.leaves[0].val
+	root := oddLeavesTree.Root().Bytes()
+	proof := oddLeavesTree.Proof(leaf).ToByteArrays()
+	fetch := func(level int) ([]byte, error) {
+		return proof[level], nil
+	}
+
+	t.Run("Should Be Verified", func(t *testing.T) {
+		if ok, err := VerifyLazy(algo, leaf, root, len(proof), fetch); !ok || err != nil {
+			t.Errorf("proof should have been valid, got %t, %v", ok, err)
+		}
+	})
+
+	t.Run("Should Not Be Verified Without Error", func(t *testing.T) {
+		if ok, err := VerifyLazy(algo, oddLeavesTree.leaves[1].val, root, len(proof), fetch); ok || err != nil {
+			t.Errorf("proof should have been invalid without error, got %t, %v", ok, err)
+		}
+	})
+
+	t.Run("Should Fail Fast On Fetch Error", func(t *testing.T) {
+		errFetch := errors.New("unavailable")
+		fetched := 0
+		_, err := VerifyLazy(algo, leaf, root, len(proof), func(level int) ([]byte, error) {
+			fetched++
+			if level == 1 {
+				return nil, errFetch
+			}
+			return proof[level], nil
+		})
+		if !errors.Is(err, errFetch) {
+			t.Errorf("expected fetch error, got %v", err)
+		}
+		if fetched != 2 {
+			t.Errorf("expected 2 fetches, got %d", fetched)
+		}
+	})
+
+	t.Run("Should Return ErrMalformedProof", func(t *testing.T) {
+		truncated := func(level int) ([]byte, error) {
+			return proof[level][:16], nil
+		}
+		if _, err := VerifyLazy(algo, leaf, root, len(proof), truncated); !errors.Is(err, ErrMalformedProof) {
+			t.Errorf("expected ErrMalformedProof, got %v", err)
+		}
+		if _, err := VerifyLazy(algo, leaf, root, -1, fetch); !errors.Is(err, ErrMalformedProof) {
+			t.Errorf("expected ErrMalformedProof for negative depth, got %v", err)
+		}
+	})
+}
+
+func TestVerifyE(t *testing.T) {
+	leaf := oddLeavesTree.leaves[0].val
+	root := oddLeavesTree.Root().Bytes()
+	proof := oddLeavesTree.Proof(leaf).ToByteArrays()
+
+	t.Run("Should Be Verified", func(t *testing.T) {
+		if ok, err := VerifyE(algo, leaf, root, proof); !ok || err != nil {
+			t.Errorf("proof should have been valid, got %t, %v", ok, err)
+		}
+	})
+
+	t.Run("Should Not Be Verified Without Error", func(t *testing.T) {
+		if ok, err := VerifyE(algo, oddLeavesTree.leaves[1].val, root, proof); ok || err != nil {
+			t.Errorf("proof should have been invalid without error, got %t, %v", ok, err)
+		}
+	})
+
+	t.Run("Should Return ErrMalformedProof", func(t *testing.T) {
+		truncated := append([][]byte{proof[0][:16]}, proof[1:]...)
+		for _, tc := range []struct {
+			name       string
+			leaf, root []byte
+			proof      [][]byte
+		}{
+			{"Truncated Proof", leaf, root, truncated},
+			{"Truncated Leaf", leaf[:16], root, proof},
+			{"Truncated Root", leaf, root[:16], proof},
+		} {
+			if _, err := VerifyE(algo, tc.leaf, tc.root, tc.proof); err != ErrMalformedProof {
+				t.Errorf("%s expected ErrMalformedProof, got %v", tc.name, err)
+			}
+		}
+	})
+}
+
+func TestShareAncestor(t *testing.T) {
+	// leaves are sorted as : 18ac.., 2e7d.., 3e23.., 3f79.., ca97..
+	l := oddLeavesTree.leaves
+	proof := func(n *Node) [][]byte {
+		return oddLeavesTree.Proof(n.val).ToByteArrays()
+	}
+
+	t.Run("Should Share Parent Of Siblings", func(t *testing.T) {
+		if !ShareAncestor(algo, l[0].val, proof(l[0]), l[1].val, proof(l[1]), l[0].parent.val) {
+			t.Errorf("expected leaves to share their parent")
+		}
+	})
+
+	t.Run("Should Not Share Parent Of Cousins", func(t *testing.T) {
+		if ShareAncestor(algo, l[1].val, proof(l[1]), l[2].val, proof(l[2]), l[1].parent.val) {
+			t.Errorf("expected leaves not to share the parent")
+		}
+	})
+
+	t.Run("Should Share Root", func(t *testing.T) {
+		if !ShareAncestor(algo, l[0].val, proof(l[0]), l[4].val, proof(l[4]), oddLeavesTree.Root().val) {
+			t.Errorf("expected leaves to share the root")
+		}
+	})
+}
+
+func TestVerifyWithDifficulty(t *testing.T) {
+	root := oddLeavesTree.Root().Bytes()
+	bits := leadingZeros(root)
+	leaf := oddLeavesTree.leaves[0].val
+	proof := oddLeavesTree.Proof(leaf).ToByteArrays()
+
+	t.Run("Should Be Verified Meeting Difficulty", func(t *testing.T) {
+		if !VerifyWithDifficulty(algo, leaf, root, proof, bits) {
+			t.Errorf("proof should have been valid")
+		}
+	})
+
+	t.Run("Should Not Be Verified Missing Difficulty", func(t *testing.T) {
+		if VerifyWithDifficulty(algo, leaf, root, proof, bits+1) {
+			t.Errorf("proof should have been invalid")
+		}
+	})
+
+	t.Run("Should Count Leading Zero Bits", func(t *testing.T) {
+		for exp, b := range map[int][]byte{
+			0:  {0x80, 0x00},
+			3:  {0x1f, 0xff},
+			11: {0x00, 0x1b},
+			16: {0x00, 0x00},
+		} {
+			if act := leadingZeros(b); act != exp {
+				t.Errorf("expected %d leading zero bits for %x, got %d", exp, b, act)
+			}
+		}
+	})
+}
+
+func TestVerifyStrict(t *testing.T) {
+	leaf := oddLeavesTree.leaves[0].val
+	root := oddLeavesTree.Root().Bytes()
+	proof := oddLeavesTree.Proof(leaf).ToByteArrays()
+
+	t.Run("Should Be Verified", func(t *testing.T) {
+		if !VerifyStrict(algo, leaf, root, proof) {
+			t.Errorf("proof should have been valid")
+		}
+	})
+
+	t.Run("Should Not Be Verified With Malformed Proof", func(t *testing.T) {
+		if VerifyStrict(algo, leaf, root, append([][]byte{proof[0][:16]}, proof[1:]...)) {
+			t.Errorf("proof should have been invalid for truncated sibling")
+		}
+		if VerifyStrict(algo, leaf, root, make([][]byte, 64)) {
+			t.Errorf("proof should have been invalid for being too long")
+		}
+	})
+}
+
+func TestVerifyRootHex(t *testing.T) {
+	for leaf, proof := range oddLeavesTreeProofs {
+		leafb, _ := hex.DecodeString(leaf)
+		proofb := hexStringsToByteArrays(proof...)
+		t.Run("Should Be Verified For "+leaf, func(t *testing.T) {
+			ok, err := VerifyRootHex(algo, leafb, oddLeavesTree.Root().Hex(), proofb)
+			if err != nil || !ok {
+				t.Errorf("proof should have been valid, got %t, %v", ok, err)
+			}
+		})
+	}
+
+	t.Run("Should Return Error For Invalid Hex", func(t *testing.T) {
+		if ok, err := VerifyRootHex(algo, oddLeavesTree.leaves[0].val, "zz", nil); ok || err == nil {
+			t.Errorf("expected an error, got %t, %v", ok, err)
+		}
+	})
+}
+
+func TestTree_TruncatedHashes(t *testing.T) {
+	for name, newHash := range map[string]func() hash.Hash{
+		"SHA-512/256": sha512.New512_256,
+		"SHA-512/224": sha512.New512_224,
+		"SHA-224":     sha256.New224,
+	} {
+		h := newHash()
+		hl := hashStringSlice(h, "a", "b", "c", "d", "e")
+		tree := NewTree(h, hl)
+		root := tree.Root().Bytes()
+
+		t.Run(name+" Should Size Root As The Digest", func(t *testing.T) {
+			if len(root) != h.Size() || tree.HashSize() != h.Size() {
+				t.Errorf("expected root size to be %d, got %d", h.Size(), len(root))
+			}
+		})
+
+		t.Run(name+" Should Verify Proofs", func(t *testing.T) {
+			for _, l := range hl {
+				proof, err := tree.Prove(l)
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				if !Verify(h, l, root, proof.ToByteArrays()) {
+					t.Errorf("proof for %x should have been valid", l)
+				}
+				if Verify(sha256.New(), l, root, proof.ToByteArrays()) {
+					t.Errorf("proof for %x should have been invalid with sha256", l)
+				}
+				if ok, err := tree.Verify(l, proof); !ok || err != nil {
+					t.Errorf("proof for %x should have been valid, got %t, %v", l, ok, err)
+				}
+			}
+		})
+
+		t.Run(name+" Should Return ErrHashSize For Sha512 Leaves", func(t *testing.T) {
+			if _, err := tree.Prove(hashStringSlice(sha512.New(), "a")[0]); err != ErrHashSize {
+				t.Errorf("expected ErrHashSize, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTree_DirtyAfterUpdate(t *testing.T) {
+	hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+	leaf := oddLeavesTree.leaves[1].val
+
+	t.Run("Should Return Nodes Changing After Update", func(t *testing.T) {
+		dirty := oddLeavesTree.DirtyAfterUpdate(leaf)
+		if len(dirty) != 3 || dirty[2] != oddLeavesTree.Root() {
+			t.Fatalf("expected 3 dirty nodes up to the root, got %d", len(dirty))
+		}
+
+		// updating the leaf keeping it at the same sorted position.
+		tree := NewTree(algo, hl)
+		tree.Update(leaf, append(append([]byte{}, leaf[:len(leaf)-1]...), leaf[len(leaf)-1]^1))
+		hashes := map[string]bool{}
+		tree.Root().WalkPreOrder(func(n *Node, _ int) {
+			hashes[n.Hex()] = true
+		})
+		isDirty := map[*Node]bool{}
+		for _, n := range dirty {
+			isDirty[n] = true
+		}
+		oddLeavesTree.Root().WalkPreOrder(func(n *Node, _ int) {
+			if n.IsLeaf() {
+				return
+			}
+			if changed := !hashes[n.Hex()]; changed != isDirty[n] {
+				t.Errorf("expected node %s to be dirty %t, got %t", n, changed, isDirty[n])
+			}
+		})
+	})
+
+	t.Run("Should Return Empty Nodes For Non Existent Leaf", func(t *testing.T) {
+		if dirty := oddLeavesTree.DirtyAfterUpdate(hashStringSlice(algo, "f")[0]); len(dirty) != 0 {
+			t.Errorf("expected no dirty nodes, got %d", len(dirty))
+		}
+	})
+}
+
+func TestTree_CommonAncestorDepth(t *testing.T) {
+	// leaves are sorted as : 18ac.., 2e7d.., 3e23.., 3f79.., ca97..
+	// where ca97.. is promoted right below the root.
+	l := oddLeavesTree.leaves.ToByteArrays()
+	for _, tc := range []struct {
+		name string
+		a, b []byte
+		exp  int
+	}{
+		{"Siblings", l[0], l[1], 2},
+		{"Cousins", l[1], l[2], 1},
+		{"Promoted", l[0], l[4], 0},
+		{"Same Leaf", l[3], l[3], 3},
+	} {
+		t.Run("Should Return Depth Of "+tc.name, func(t *testing.T) {
+			act, err := oddLeavesTree.CommonAncestorDepth(tc.a, tc.b)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if act != tc.exp {
+				t.Errorf("expected depth %d, got %d", tc.exp, act)
+			}
+		})
+	}
+
+	t.Run("Should Return ErrLeafNotFound", func(t *testing.T) {
+		if _, err := oddLeavesTree.CommonAncestorDepth(l[0], hashStringSlice(algo, "f")[0]); err != ErrLeafNotFound {
+			t.Errorf("expected ErrLeafNotFound, got %v", err)
+		}
+	})
+}
+
+func TestNewTreeFromPacked(t *testing.T) {
+	packed := bytes.Join(hashStringSlice(algo, "a", "b", "c", "d", "e"), nil)
+
+	t.Run("Should Return Expected Merkle Root", func(t *testing.T) {
+		tree, err := NewTreeFromPacked(algo, packed, algo.Size())
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if exp, act := oddLeavesTree.Root().String(), tree.Root().String(); act != exp {
+			t.Errorf("expected merkle root should have been %s, got %s", exp, act)
+		}
+	})
+
+	t.Run("Should Return ErrHashSize", func(t *testing.T) {
+		if _, err := NewTreeFromPacked(algo, packed[1:], algo.Size()); err != ErrHashSize {
+			t.Errorf("expected ErrHashSize, got %v", err)
+		}
+	})
+
+	t.Run("Should Return ErrNoLeaves", func(t *testing.T) {
+		if _, err := NewTreeFromPacked(algo, nil, algo.Size()); err != ErrNoLeaves {
+			t.Errorf("expected ErrNoLeaves, got %v", err)
+		}
+	})
+}
+
+func TestNewTreeFunc(t *testing.T) {
+	tree := NewTreeFunc(sha256.New, hashStringSlice(algo, "a", "b", "c", "d", "e"))
+	if exp, act := oddLeavesTree.Root().String(), tree.Root().String(); act != exp {
+		t.Errorf("expected merkle root should have been %s, got %s", exp, act)
+	}
+}
+
+// TestTree_ConcurrentProofs is meant to be run with -race.
+func TestTree_ConcurrentProofs(t *testing.T) {
+	tree := NewTree(sha256.New(), hashStringSlice(sha256.New(), "a", "b", "c", "d", "e", "f", "g"))
+	root := tree.Root().Bytes()
+	leaves := tree.leaves.ToByteArrays()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			// each goroutine verifies with its own hashing algorithm.
+			h := sha256.New()
+			for i := 0; i < 100; i++ {
+				leaf := leaves[(g+i)%len(leaves)]
+				if !Verify(h, leaf, root, tree.Proof(leaf).ToByteArrays()) {
+					t.Errorf("proof for %x should have been valid", leaf)
+				}
+				tree.Neighbors(leaf)
+				tree.Snapshot()
+				tree.Root().Graphify(io.Discard)
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func TestTree_Insert(t *testing.T) {
+	tree := NewTree(algo, hashStringSlice(algo, "e", "b", "c"))
+	for _, l := range hashStringSlice(algo, "a", "d") {
+		tree.Insert(l)
+	}
+
+	t.Run("Should Keep Leaves Sorted", func(t *testing.T) {
+		for i := range oddLeavesTree.leaves {
+			if exp, act := oddLeavesTree.leaves[i].Hex(), tree.leaves[i].Hex(); act != exp {
+				t.Errorf("expected leaf at index %d to be %s, got %s", i, exp, act)
+			}
+		}
+	})
+
+	t.Run("Should Return Expected Merkle Root", func(t *testing.T) {
+		if exp, act := oddLeavesTree.Root().String(), tree.Root().String(); act != exp {
+			t.Errorf("expected merkle root should have been %s, got %s", exp, act)
+		}
+	})
+
+	t.Run("Should Build Valid Proofs", func(t *testing.T) {
+		for _, l := range tree.leaves {
+			if !Verify(algo, l.val, tree.Root().Bytes(), tree.Proof(l.val).ToByteArrays()) {
+				t.Errorf("proof for leaf %s should have been valid", l)
+			}
+		}
+	})
+
+	t.Run("Should Pad Leaves Again", func(t *testing.T) {
+		hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+		tree := NewTree(algo, hl[:4], WithBlindingPadding(4, nil))
+		if err := tree.Insert(hl[4]); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if len(tree.leaves) != 8 || len(tree.padding) != 3 {
+			t.Errorf("expected 8 leaves, 3 of which padding, got %d and %d", len(tree.leaves), len(tree.padding))
+		}
+		for _, l := range hl {
+			if !Verify(algo, l, tree.Root().Bytes(), tree.Proof(l).ToByteArrays()) {
+				t.Errorf("proof for leaf %x should have been valid", l)
+			}
+		}
+	})
+
+	t.Run("Should Return ErrMaxDepth", func(t *testing.T) {
+		hl := hashStringSlice(algo, "a", "b", "c", "d", "e")
+		for name, opts := range map[string][]Option{
+			"Default":          {WithMaxDepth(2)},
+			"Empty Padding":    {WithMaxDepth(2), WithEmptyHashPadding()},
+			"Multiplicities":   {WithMaxDepth(2), WithMultiplicities()},
+			"Ordered Mode":     {WithMaxDepth(2), WithMode(ModeOrdered)},
+			"Blinding Padding": {WithMaxDepth(2), WithBlindingPadding(2, nil)},
+		} {
+			tree := NewTree(algo, hl[:4], opts...)
+			root := tree.Root().String()
+			if err := tree.Insert(hl[4]); err != ErrMaxDepth {
+				t.Errorf("%s: expected ErrMaxDepth, got %v", name, err)
+			}
+			if act := tree.Root().String(); act != root {
+				t.Errorf("%s: expected merkle root to be left untouched", name)
+			}
+		}
+	})
+}
+
+func TestVerify_SingleLeaf(t *testing.T) {
+	leaf := hashStringSlice(algo, "a")[0]
+	tree := NewTree(algo, [][]byte{leaf})
+
+	t.Run("Should Have The Leaf As Merkle Root", func(t *testing.T) {
+		if !bytes.Equal(tree.Root().Bytes(), leaf) {
+			t.Errorf("expected merkle root to be %x, got %s", leaf, tree.Root())
+		}
+	})
+
+	t.Run("Should Return Empty Proof", func(t *testing.T) {
+		if proof, _, ok := tree.ProofWithIndex(leaf); !ok || len(proof) > 0 {
+			t.Errorf("expected empty proof for found leaf, got %d, %t", len(proof), ok)
+		}
+	})
+
+	t.Run("Should Verify Empty Proof", func(t *testing.T) {
+		if !Verify(algo, leaf, tree.Root().Bytes(), [][]byte{}) {
+			t.Errorf("proof should have been valid")
+		}
+		if !Verify(algo, leaf, tree.Root().Bytes(), nil) {
+			t.Errorf("nil proof should have been valid")
+		}
+	})
+
+	t.Run("Should Not Verify Empty Proof For Another Leaf", func(t *testing.T) {
+		if Verify(algo, hashStringSlice(algo, "b")[0], tree.Root().Bytes(), nil) {
+			t.Errorf("proof should have been invalid")
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package merkle
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Wire tags tell apart the kind of Node in its wire format.
+const (
+	WireLeaf  byte = 0x00
+	WireInner byte = 0x01
+)
+
+// ErrWireFormat is returned when unmarshalling a malformed wire Node.
+var ErrWireFormat = errors.New("merkle: malformed wire node")
+
+// MarshalWire encodes the Node into a compact wire format made of
+// a tag byte, either WireLeaf or WireInner, the hash length
+// as an unsigned varint and the hash bytes, in this order.
+//
+// It's meant to stream single nodes between peers,
+// e.g. while reconciling trees through a gossip protocol.
+func (n *Node) MarshalWire() []byte {
+	tag := WireInner
+	if n.IsLeaf() {
+		tag = WireLeaf
+	}
+	b := make([]byte, 1+binary.MaxVarintLen64+len(n.val))
+	b[0] = tag
+	l := 1 + binary.PutUvarint(b[1:], uint64(len(n.val)))
+	return append(b[:l], n.val...)
+}
+
+// UnmarshalWireNode decodes a Node from the beginning of data encoded with
+// MarshalWire, returning it along with the number of bytes consumed so
+// that multiple nodes can be read from the same stream of data.
+// The returned Node is detached, hence it has no parent nor children,
+// the kind of node can be told from the tag at data[0].
+func UnmarshalWireNode(data []byte) (*Node, int, error) {
+	if len(data) < 1 || (data[0] != WireLeaf && data[0] != WireInner) {
+		return nil, 0, ErrWireFormat
+	}
+	size, n := binary.Uvarint(data[1:])
+	if n <= 0 {
+		return nil, 0, ErrWireFormat
+	}
+	start := 1 + n
+	if uint64(len(data)-start) < size {
+		return nil, 0, ErrWireFormat
+	}
+	end := start + int(size)
+	val := make([]byte, size)
+	copy(val, data[start:end])
+	return newNode(val), end, nil
+}
@@ -0,0 +1,57 @@
+package merkle
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNode_MarshalWire(t *testing.T) {
+	t.Run("Should Encode Leaf", func(t *testing.T) {
+		exp := []byte{WireLeaf, 3, 'f', 'o', 'o'}
+		if act := newNode([]byte("foo")).MarshalWire(); !bytes.Equal(act, exp) {
+			t.Errorf("expected %v, got %v", exp, act)
+		}
+	})
+	t.Run("Should Encode Inner Node", func(t *testing.T) {
+		root := oddLeavesTree.Root()
+		act := root.MarshalWire()
+		if act[0] != WireInner || act[1] != 32 || !bytes.Equal(act[2:], root.val) {
+			t.Errorf("unexpected wire inner node %v", act)
+		}
+	})
+}
+
+func TestUnmarshalWireNode(t *testing.T) {
+	t.Run("Should Decode Streamed Nodes", func(t *testing.T) {
+		var stream []byte
+		for _, l := range oddLeavesTree.leaves {
+			stream = append(stream, l.MarshalWire()...)
+		}
+		stream = append(stream, oddLeavesTree.Root().MarshalWire()...)
+		exp := append(Nodes{}, oddLeavesTree.leaves...)
+		exp = append(exp, oddLeavesTree.Root())
+		for i := 0; len(stream) > 0; i++ {
+			n, read, err := UnmarshalWireNode(stream)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !bytes.Equal(n.val, exp[i].val) {
+				t.Errorf("expected node at %d to be %s, got %s", i, exp[i], n)
+			}
+			stream = stream[read:]
+		}
+	})
+	for name, data := range map[string][]byte{
+		"Empty":         {},
+		"Unknown Tag":   {0x02, 1, 'a'},
+		"Missing Size":  {WireLeaf},
+		"Truncated":     {WireLeaf, 3, 'f', 'o'},
+		"Overflow Size": {WireLeaf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01},
+	} {
+		t.Run("Should Return ErrWireFormat With "+name+" Data", func(t *testing.T) {
+			if _, _, err := UnmarshalWireNode(data); err != ErrWireFormat {
+				t.Errorf("expected ErrWireFormat, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package merkle
+
+import (
+	"bytes"
+)
+
+// Witness is the minimal evidence of where two trees diverge, that is, the
+// root of the smallest subtree holding every difference in each tree along
+// with its proof up to the root of its own tree. Trees built alike share
+// the siblings above such subtree, hence the proofs match, proving the
+// trees are equal everywhere else.
+type Witness struct {
+	// A and B are the roots of the diverging subtree in each tree.
+	A, B *Node
+	// ProofA and ProofB prove A and B up to the root of their tree.
+	ProofA, ProofB Nodes
+	// Depth is the depth of the diverging subtree, the root being at 0.
+	Depth int
+}
+
+// DivergenceWitness descends both trees from their roots, following the
+// only pair of children differing as long as there's one, and returns the
+// Witness of the subtree where they diverge. It returns a nil Witness if
+// the trees are equal, ErrTreeSize if they have a different number of
+// leaves, hence a different shape, and ErrNoLeaves if either of them
+// was built WithoutLeaves. The returned Nodes are the ones of the trees.
+//
+// In modes sorting children pairs, different leaves may be laid out at
+// different sides, in which case the Witness is still valid but it may
+// not be the smallest one.
+func DivergenceWitness(a, b *Tree) (*Witness, error) {
+	if a.c.withoutLeaves || b.c.withoutLeaves {
+		return nil, ErrNoLeaves
+	}
+	if len(a.leaves) != len(b.leaves) {
+		return nil, ErrTreeSize
+	}
+	a.expand()
+	b.expand()
+	na, nb := a.root, b.root
+	if bytes.Equal(na.val, nb.val) {
+		return nil, nil
+	}
+
+	depth := 0
+	for !na.IsLeaf() && !nb.IsLeaf() {
+		leftEqual := bytes.Equal(na.left.val, nb.left.val)
+		rightEqual := bytes.Equal(na.right.val, nb.right.val)
+		if leftEqual == rightEqual {
+			// either both children differ, or they're swapped.
+			break
+		}
+		if leftEqual {
+			na, nb = na.right, nb.right
+		} else {
+			na, nb = na.left, nb.left
+		}
+		depth++
+	}
+
+	return &Witness{
+		A:      na,
+		B:      nb,
+		ProofA: a.nodeProof(na, Nodes{}),
+		ProofB: b.nodeProof(nb, Nodes{}),
+		Depth:  depth,
+	}, nil
+}
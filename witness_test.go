@@ -0,0 +1,78 @@
+package merkle
+
+import (
+	"testing"
+)
+
+func TestDivergenceWitness(t *testing.T) {
+	t.Run("Should Return Nil For Equal Trees", func(t *testing.T) {
+		a := NewTreeFromData(algo, [][]byte{[]byte("a"), []byte("b"), []byte("c")}, WithMode(ModeOrdered))
+		b := NewTreeFromData(algo, [][]byte{[]byte("a"), []byte("b"), []byte("c")}, WithMode(ModeOrdered))
+		w, err := DivergenceWitness(a, b)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if w != nil {
+			t.Errorf("expected no witness, got %v", w)
+		}
+	})
+
+	t.Run("Should Return The Diverging Subtree", func(t *testing.T) {
+		data := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d"), []byte("e"), []byte("f"), []byte("g"), []byte("h")}
+		a := NewTreeFromData(algo, data, WithMode(ModeOrdered))
+		changed := append([][]byte{}, data...)
+		changed[5] = []byte("x")
+		changed[4] = []byte("y")
+		b := NewTreeFromData(algo, changed, WithMode(ModeOrdered))
+
+		w, err := DivergenceWitness(a, b)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if w.Depth != 2 {
+			t.Errorf("expected depth to be 2, got %d", w.Depth)
+		}
+		if w.A != a.leaves[4].parent || w.B != b.leaves[4].parent {
+			t.Errorf("expected the witness to be the parent of leaves 4 and 5")
+		}
+		if len(w.ProofA) != 2 || len(w.ProofB) != 2 {
+			t.Fatalf("expected proofs of 2 nodes, got %d and %d", len(w.ProofA), len(w.ProofB))
+		}
+		for i := range w.ProofA {
+			if w.ProofA[i].String() != w.ProofB[i].String() {
+				t.Errorf("expected proofs to share node at index %d", i)
+			}
+		}
+		if !a.c.verifyAt(algo, w.A.Bytes(), a.Root().Bytes(), w.ProofA.ToByteArrays(), 2, 4) {
+			t.Errorf("proof of A should have been valid")
+		}
+		if !b.c.verifyAt(algo, w.B.Bytes(), b.Root().Bytes(), w.ProofB.ToByteArrays(), 2, 4) {
+			t.Errorf("proof of B should have been valid")
+		}
+	})
+
+	t.Run("Should Stop Where Both Children Differ", func(t *testing.T) {
+		a := NewTreeFromData(algo, [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}, WithMode(ModeOrdered))
+		b := NewTreeFromData(algo, [][]byte{[]byte("x"), []byte("b"), []byte("c"), []byte("y")}, WithMode(ModeOrdered))
+		w, err := DivergenceWitness(a, b)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if w.Depth != 0 || w.A != a.root || w.B != b.root || len(w.ProofA) != 0 {
+			t.Errorf("expected the witness to be the roots")
+		}
+	})
+
+	t.Run("Should Return ErrTreeSize", func(t *testing.T) {
+		if _, err := DivergenceWitness(oddLeavesTree, evenLeavesTree); err != ErrTreeSize {
+			t.Errorf("expected ErrTreeSize, got %v", err)
+		}
+	})
+
+	t.Run("Should Return ErrNoLeaves", func(t *testing.T) {
+		a := NewTree(algo, oddLeavesTree.leaves.ToByteArrays(), WithoutLeaves())
+		if _, err := DivergenceWitness(a, oddLeavesTree); err != ErrNoLeaves {
+			t.Errorf("expected ErrNoLeaves, got %v", err)
+		}
+	})
+}